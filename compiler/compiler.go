@@ -0,0 +1,125 @@
+package compiler
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/larkvincer/dsl-fsm/diagnostics"
+	"github.com/larkvincer/dsl-fsm/generator"
+	"github.com/larkvincer/dsl-fsm/lexer"
+	"github.com/larkvincer/dsl-fsm/optimizer"
+	"github.com/larkvincer/dsl-fsm/parser"
+	"github.com/larkvincer/dsl-fsm/scxml"
+	"github.com/larkvincer/dsl-fsm/semanticanalyzer"
+	"github.com/larkvincer/dsl-fsm/tokens"
+)
+
+var ErrCompilationFailed = errors.New("compilation failed")
+
+// Source formats Compile reads.
+const (
+	FormatSM    = "sm"
+	FormatSCXML = "scxml"
+)
+
+// Options selects the source format, the code generator and its flags. An
+// empty Format means FormatSM and an empty Language stops the pipeline
+// after optimization. FileName is only used to fill in the location of
+// diagnostics.
+type Options struct {
+	FileName string
+	Format   string
+	Language string
+	Flags    map[string]string
+}
+
+// FormatOf guesses the source format from a file name: FormatSCXML for
+// .scxml files, FormatSM for anything else.
+func FormatOf(fileName string) string {
+	if filepath.Ext(fileName) == ".scxml" {
+		return FormatSCXML
+	}
+	return FormatSM
+}
+
+// Result holds the output of every stage that ran. Stages after the first
+// one that reported errors are left nil.
+type Result struct {
+	Syntax      *parser.FsmSyntax
+	Semantic    *semanticanalyzer.SemanticStateMachine
+	Optimized   *optimizer.OptimizedStateMachine
+	Artifacts   []generator.Artifact
+	Diagnostics []diagnostics.Diagnostic
+}
+
+// Compile runs the whole pipeline over src. It returns ErrCompilationFailed
+// when the source has syntax or semantic errors, or is an SCXML document
+// the reader rejects; any other error means the pipeline could not run at
+// all or the generator failed.
+func Compile(src io.Reader, opts Options) (Result, error) {
+	source, err := io.ReadAll(src)
+	if err != nil {
+		return Result{}, err
+	}
+
+	var codeGenerator generator.Generator
+	if opts.Language != "" {
+		codeGenerator, err = generator.NewGenerator(opts.Language, opts.Flags)
+		if err != nil {
+			return Result{}, err
+		}
+	}
+
+	result := Result{}
+	switch opts.Format {
+	case "", FormatSM:
+		result.Syntax = Parse(string(source))
+	case FormatSCXML:
+		result.Syntax, err = scxml.Read(bytes.NewReader(source))
+		var readError *scxml.Error
+		if errors.As(err, &readError) {
+			result.addDiagnostics([]diagnostics.Diagnostic{readError.Diagnostic()}, opts.FileName)
+			return result, ErrCompilationFailed
+		}
+		if err != nil {
+			return result, err
+		}
+	default:
+		return result, fmt.Errorf("unknown source format %q, expected %s or %s", opts.Format, FormatSM, FormatSCXML)
+	}
+	result.addDiagnostics(result.Syntax.Diagnostics(), opts.FileName)
+	if len(result.Syntax.Errors) > 0 {
+		return result, ErrCompilationFailed
+	}
+
+	result.Semantic = semanticanalyzer.New().Analyze(result.Syntax)
+	result.addDiagnostics(result.Semantic.Diagnostics(), opts.FileName)
+	if len(result.Semantic.Errors) > 0 {
+		return result, ErrCompilationFailed
+	}
+
+	result.Optimized = optimizer.Optimize(*result.Semantic)
+	if codeGenerator != nil {
+		result.Artifacts, err = codeGenerator.Generate(
+			&generator.StateMachine{Semantic: result.Semantic, Optimized: result.Optimized},
+		)
+	}
+	return result, err
+}
+
+func (result *Result) addDiagnostics(stageDiagnostics []diagnostics.Diagnostic, fileName string) {
+	result.Diagnostics = append(result.Diagnostics, diagnostics.WithFile(stageDiagnostics, fileName)...)
+	diagnostics.Sort(result.Diagnostics)
+}
+
+func Parse(source string) *parser.FsmSyntax {
+	syntaxBuilder := parser.NewFsmSyntaxBuilder()
+	parser := parser.NewParser(syntaxBuilder)
+	lexer := lexer.New(parser)
+	lexer.Lex(source)
+	parser.HandleEvent(tokens.EOF, -1, -1)
+	return syntaxBuilder.GetFSM()
+}
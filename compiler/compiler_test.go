@@ -0,0 +1,194 @@
+package compiler
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/larkvincer/dsl-fsm/diagnostics"
+	"github.com/larkvincer/dsl-fsm/scxml"
+	"github.com/larkvincer/dsl-fsm/semanticanalyzer"
+)
+
+const turnstile = "" +
+	"Initial: Locked\n" +
+	"Actions: Turnstile\n" +
+	"FSM: TurnstileFSM\n" +
+	"{\n" +
+	"  Locked {\n" +
+	"    Coin Unlocked unlock\n" +
+	"    Pass Locked   alarm\n" +
+	"  }\n" +
+	"  Unlocked {\n" +
+	"    Coin Unlocked thankyou\n" +
+	"    Pass Locked   lock\n" +
+	"  }\n" +
+	"}"
+
+func TestCompile(t *testing.T) {
+	t.Run("all stages run for a valid source", func(t *testing.T) {
+		result, err := Compile(strings.NewReader(turnstile), Options{Language: "java", Flags: map[string]string{"package": "firsttry"}})
+		if err != nil {
+			t.Fatalf("expected no error, but got %v", err)
+		}
+		if result.Syntax == nil || result.Semantic == nil || result.Optimized == nil {
+			t.Fatalf("expected every stage to produce output, but got %+v", result)
+		}
+		if len(result.Diagnostics) != 0 {
+			t.Fatalf("expected no diagnostics, but got %v", result.Diagnostics)
+		}
+		if len(result.Artifacts) != 1 || result.Artifacts[0].Name != "firsttry/TurnstileFSM.java" {
+			t.Fatalf("expected firsttry/TurnstileFSM.java, but got %v", result.Artifacts)
+		}
+		if !strings.Contains(result.Artifacts[0].Content, "package firsttry;") {
+			t.Fatalf("expected package declaration, but got %s", result.Artifacts[0].Content)
+		}
+	})
+
+	t.Run("no language stops after optimization", func(t *testing.T) {
+		result, err := Compile(strings.NewReader(turnstile), Options{})
+		if err != nil {
+			t.Fatalf("expected no error, but got %v", err)
+		}
+		if result.Optimized == nil || len(result.Artifacts) != 0 {
+			t.Fatalf("expected optimized machine without artifacts, but got %+v", result)
+		}
+	})
+
+	t.Run("syntax errors stop the pipeline", func(t *testing.T) {
+		result, err := Compile(strings.NewReader("{s e}"), Options{Language: "java"})
+		if !errors.Is(err, ErrCompilationFailed) {
+			t.Fatalf("expected ErrCompilationFailed, but got %v", err)
+		}
+		if result.Semantic != nil || len(result.Diagnostics) == 0 {
+			t.Fatalf("expected only syntax diagnostics, but got %+v", result)
+		}
+	})
+
+	t.Run("semantic errors stop the pipeline", func(t *testing.T) {
+		result, err := Compile(strings.NewReader("{s e s2 *}"), Options{Language: "java"})
+		if !errors.Is(err, ErrCompilationFailed) {
+			t.Fatalf("expected ErrCompilationFailed, but got %v", err)
+		}
+		if result.Optimized != nil || !containsDiagnostic(result.Diagnostics, semanticanalyzer.UNDEFINED_STATE) {
+			t.Fatalf("expected UNDEFINED_STATE diagnostic, but got %v", result.Diagnostics)
+		}
+	})
+
+	t.Run("diagnostics carry the file name and severity", func(t *testing.T) {
+		result, _ := Compile(strings.NewReader("{(s) e * * s e * *}"), Options{FileName: "machine.sm"})
+		for _, diagnostic := range result.Diagnostics {
+			if diagnostic.Location.File != "machine.sm" {
+				t.Fatalf("expected file machine.sm, but got %v", diagnostic)
+			}
+			if diagnostic.Code == string(semanticanalyzer.INCONSISTENT_ABSTRACTION) && diagnostic.Severity != diagnostics.Warning {
+				t.Fatalf("expected warning severity, but got %v", diagnostic)
+			}
+		}
+		if !containsDiagnostic(result.Diagnostics, semanticanalyzer.INCONSISTENT_ABSTRACTION) {
+			t.Fatalf("expected INCONSISTENT_ABSTRACTION warning, but got %v", result.Diagnostics)
+		}
+	})
+
+	t.Run("SCXML sources go through semantic analysis", func(t *testing.T) {
+		source := `<scxml xmlns="http://www.w3.org/2005/07/scxml" name="TurnstileFSM" initial="Locked">
+  <state id="Locked"><transition event="Coin" target="Unlocked"><script>unlock();</script></transition></state>
+  <state id="Unlocked"><transition event="Pass" target="Gone"/></state>
+</scxml>`
+		result, err := Compile(strings.NewReader(source), Options{FileName: "turnstile.scxml", Format: FormatSCXML})
+		if !errors.Is(err, ErrCompilationFailed) || !containsDiagnostic(result.Diagnostics, semanticanalyzer.UNDEFINED_STATE) {
+			t.Fatalf("expected UNDEFINED_STATE diagnostic, but got %v, %v", err, result.Diagnostics)
+		}
+		if location := result.Diagnostics[0].Location; location.File != "turnstile.scxml" || location.Line != 3 {
+			t.Fatalf("expected the diagnostic at turnstile.scxml:3, but got %v", location)
+		}
+
+		result, err = Compile(strings.NewReader(strings.Replace(source, "Gone", "Locked", 1)), Options{Format: FormatSCXML})
+		if err != nil {
+			t.Fatalf("expected no error, but got %v", err)
+		}
+		if result.Optimized.Header.Fsm != "TurnstileFSM" || len(result.Optimized.Transitions) != 2 {
+			t.Fatalf("expected the optimized SCXML machine, but got %v", result.Optimized)
+		}
+	})
+
+	t.Run("SCXML transitions to compound states enter their initial substate", func(t *testing.T) {
+		source := `<scxml xmlns="http://www.w3.org/2005/07/scxml" name="f" initial="P">
+  <state id="P"><state id="c"><transition event="e" target="P"/></state></state>
+</scxml>`
+		result, err := Compile(strings.NewReader(source), Options{Format: FormatSCXML})
+		if err != nil {
+			t.Fatalf("expected no error, but got %v: %v", err, result.Diagnostics)
+		}
+		if len(result.Diagnostics) != 0 || result.Optimized.Transitions[0].SubTransitions[0].NextState != "c" {
+			t.Fatalf("expected c to re-enter itself, but got %v, %v", result.Diagnostics, result.Optimized)
+		}
+	})
+
+	t.Run("SCXML targetless transitions of compound states are rejected", func(t *testing.T) {
+		source := `<scxml xmlns="http://www.w3.org/2005/07/scxml" name="f" initial="a">
+  <state id="Base">
+    <transition event="ping"><script>pong();</script></transition>
+    <state id="a"><transition event="e" target="b"/></state>
+    <state id="b"><transition event="e" target="a"/></state>
+  </state>
+</scxml>`
+		result, err := Compile(strings.NewReader(source), Options{FileName: "f.scxml", Format: FormatSCXML, Language: "go"})
+		if !errors.Is(err, ErrCompilationFailed) || len(result.Artifacts) != 0 {
+			t.Fatalf("expected compilation to fail, but got %v, %v", err, result.Artifacts)
+		}
+		expected := diagnostics.Location{File: "f.scxml", Line: 3, Column: 6, EndLine: 3, EndColumn: 15}
+		if len(result.Diagnostics) != 1 || result.Diagnostics[0].Code != scxml.UNSUPPORTED_SCXML ||
+			result.Diagnostics[0].Location != expected {
+			t.Fatalf("expected an %s diagnostic at %v, but got %v", scxml.UNSUPPORTED_SCXML, expected, result.Diagnostics)
+		}
+	})
+
+	t.Run("unreadable SCXML", func(t *testing.T) {
+		for document, code := range map[string]string{
+			"<scxml><parallel/></scxml>": scxml.UNSUPPORTED_SCXML,
+			"<scxml><state id='a'>":      scxml.INVALID_XML,
+		} {
+			result, err := Compile(strings.NewReader(document), Options{FileName: "f.scxml", Format: FormatSCXML})
+			if !errors.Is(err, ErrCompilationFailed) || len(result.Diagnostics) != 1 ||
+				result.Diagnostics[0].Code != code || result.Diagnostics[0].Location.File != "f.scxml" {
+				t.Fatalf("expected an %s diagnostic for %s, but got %v, %v", code, document, err, result.Diagnostics)
+			}
+		}
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		_, err := Compile(strings.NewReader(turnstile), Options{Format: "xmi"})
+		if err == nil || errors.Is(err, ErrCompilationFailed) {
+			t.Fatalf("expected unknown format error, but got %v", err)
+		}
+	})
+
+	t.Run("unknown language", func(t *testing.T) {
+		_, err := Compile(strings.NewReader(turnstile), Options{Language: "cobol"})
+		if err == nil || errors.Is(err, ErrCompilationFailed) {
+			t.Fatalf("expected unknown generator error, but got %v", err)
+		}
+	})
+}
+
+func TestFormatOf(t *testing.T) {
+	for fileName, format := range map[string]string{
+		"turnstile.sm":           FormatSM,
+		"models/turnstile.scxml": FormatSCXML,
+		"<stdin>":                FormatSM,
+	} {
+		if actual := FormatOf(fileName); actual != format {
+			t.Errorf("expected %s for %s, but got %s", format, fileName, actual)
+		}
+	}
+}
+
+func containsDiagnostic(diagnostics []diagnostics.Diagnostic, code semanticanalyzer.ErrorId) bool {
+	for _, diagnostic := range diagnostics {
+		if diagnostic.Code == string(code) {
+			return true
+		}
+	}
+	return false
+}
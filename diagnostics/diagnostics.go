@@ -0,0 +1,127 @@
+package diagnostics
+
+import (
+	"fmt"
+	"sort"
+)
+
+type Severity int
+
+const (
+	Error Severity = iota
+	Warning
+)
+
+func (severity Severity) String() string {
+	switch severity {
+	case Error:
+		return "error"
+	case Warning:
+		return "warning"
+	}
+	return fmt.Sprintf("Severity(%d)", int(severity))
+}
+
+// Location is a span in a source file. Lines and columns are 1-based and
+// the end column is inclusive; zero means the position is unknown.
+type Location struct {
+	File      string
+	Line      int
+	Column    int
+	EndLine   int
+	EndColumn int
+}
+
+func (location Location) String() string {
+	result := location.File
+	if location.Line > 0 {
+		result += fmt.Sprintf(":%d", location.Line)
+		if location.Column > 0 {
+			result += fmt.Sprintf(":%d", location.Column)
+		}
+	}
+	return result
+}
+
+func Span(start, end Location) Location {
+	if end.Line == 0 {
+		return start
+	}
+	if start.Line == 0 {
+		return end
+	}
+	return Location{
+		File:      start.File,
+		Line:      start.Line,
+		Column:    start.Column,
+		EndLine:   end.EndLine,
+		EndColumn: end.EndColumn,
+	}
+}
+
+type Diagnostic struct {
+	Severity Severity
+	Code     string
+	Message  string
+	Location Location
+	Related  []Location
+}
+
+func (diagnostic Diagnostic) String() string {
+	result := fmt.Sprintf("%s: %s (%s)", diagnostic.Severity, diagnostic.Message, diagnostic.Code)
+	if location := diagnostic.Location.String(); location != "" {
+		result = location + ": " + result
+	}
+	return result
+}
+
+func HasErrors(diagnostics []Diagnostic) bool {
+	for _, diagnostic := range diagnostics {
+		if diagnostic.Severity == Error {
+			return true
+		}
+	}
+	return false
+}
+
+func Filter(diagnostics []Diagnostic, keep func(Diagnostic) bool) []Diagnostic {
+	filtered := []Diagnostic{}
+	for _, diagnostic := range diagnostics {
+		if keep(diagnostic) {
+			filtered = append(filtered, diagnostic)
+		}
+	}
+	return filtered
+}
+
+func WithFile(diagnostics []Diagnostic, file string) []Diagnostic {
+	for i := range diagnostics {
+		diagnostics[i].Location.File = file
+		for j := range diagnostics[i].Related {
+			diagnostics[i].Related[j].File = file
+		}
+	}
+	return diagnostics
+}
+
+// Sort orders diagnostics by file and position, then errors before
+// warnings, then by code. The sort is stable, so diagnostics that compare
+// equal keep the order in which the stages reported them.
+func Sort(diagnostics []Diagnostic) {
+	sort.SliceStable(diagnostics, func(i, j int) bool {
+		left, right := diagnostics[i], diagnostics[j]
+		if left.Location.File != right.Location.File {
+			return left.Location.File < right.Location.File
+		}
+		if left.Location.Line != right.Location.Line {
+			return left.Location.Line < right.Location.Line
+		}
+		if left.Location.Column != right.Location.Column {
+			return left.Location.Column < right.Location.Column
+		}
+		if left.Severity != right.Severity {
+			return left.Severity < right.Severity
+		}
+		return left.Code < right.Code
+	})
+}
@@ -0,0 +1,164 @@
+package diagnostics
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestString(t *testing.T) {
+	type stringTest struct {
+		name       string
+		diagnostic Diagnostic
+		expected   string
+	}
+
+	testTable := []stringTest{
+		{"no location", Diagnostic{Severity: Error, Code: "NO_FSM", Message: "no FSM header"},
+			"error: no FSM header (NO_FSM)"},
+		{"file only", Diagnostic{Severity: Warning, Code: "W", Message: "m", Location: Location{File: "f.sm"}},
+			"f.sm: warning: m (W)"},
+		{"file and position", Diagnostic{Severity: Error, Code: "E", Message: "m", Location: Location{File: "f.sm", Line: 3, Column: 7}},
+			"f.sm:3:7: error: m (E)"},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			got := testCase.diagnostic.String()
+			if got != testCase.expected {
+				t.Fatalf("expected '%s', but got '%s'", testCase.expected, got)
+			}
+		})
+	}
+}
+
+func TestSort(t *testing.T) {
+	got := []Diagnostic{
+		{Severity: Warning, Code: "W", Location: Location{File: "a", Line: 2, Column: 1}},
+		{Severity: Error, Code: "B", Location: Location{File: "b", Line: 1, Column: 1}},
+		{Severity: Error, Code: "E", Location: Location{File: "a", Line: 2, Column: 1}},
+		{Severity: Error, Code: "D", Location: Location{File: "a", Line: 1, Column: 5}},
+		{Severity: Error, Code: "C", Location: Location{File: "a", Line: 1, Column: 5}},
+	}
+	Sort(got)
+
+	codes := []string{}
+	for _, diagnostic := range got {
+		codes = append(codes, diagnostic.Code)
+	}
+	expected := []string{"C", "D", "E", "W", "B"}
+	if !reflect.DeepEqual(codes, expected) {
+		t.Fatalf("expected %v, but got %v", expected, codes)
+	}
+}
+
+func TestFilterAndHasErrors(t *testing.T) {
+	all := []Diagnostic{{Severity: Warning, Code: "W"}, {Severity: Error, Code: "E"}}
+
+	warnings := Filter(all, func(diagnostic Diagnostic) bool { return diagnostic.Severity == Warning })
+	if len(warnings) != 1 || warnings[0].Code != "W" {
+		t.Fatalf("expected only the warning, but got %v", warnings)
+	}
+	if HasErrors(warnings) {
+		t.Fatalf("expected no errors in %v", warnings)
+	}
+	if !HasErrors(all) {
+		t.Fatalf("expected errors in %v", all)
+	}
+}
+
+func TestWithFile(t *testing.T) {
+	got := WithFile([]Diagnostic{{Code: "E", Related: []Location{{Line: 1}}}}, "f.sm")
+	if got[0].Location.File != "f.sm" || got[0].Related[0].File != "f.sm" {
+		t.Fatalf("expected every location in f.sm, but got %v", got)
+	}
+}
+
+var emitted = []Diagnostic{
+	{
+		Severity: Error,
+		Code:     "DUPLICATE_TRANSITION",
+		Message:  "duplicate transition s(e)",
+		Location: Location{File: "dir/f.sm", Line: 4, Column: 5, EndLine: 4, EndColumn: 6},
+		Related:  []Location{{File: "dir/f.sm", Line: 3, Column: 5, EndLine: 3, EndColumn: 6}},
+	},
+	{Severity: Warning, Code: "INCONSISTENT_ABSTRACTION", Message: "m", Location: Location{File: "dir/f.sm"}},
+	{Severity: Error, Code: "NO_FSM", Message: "no FSM header"},
+}
+
+func TestWriteJSON(t *testing.T) {
+	var output bytes.Buffer
+	if err := WriteJSON(&output, emitted); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []map[string]interface{}
+	if err := json.Unmarshal(output.Bytes(), &got); err != nil {
+		t.Fatalf("expected valid JSON, but got %v: %s", err, output.String())
+	}
+	if len(got) != 3 {
+		t.Fatalf("expected 3 entries, but got %s", output.String())
+	}
+	first := got[0]
+	if first["severity"] != "error" || first["code"] != "DUPLICATE_TRANSITION" || first["file"] != "dir/f.sm" ||
+		first["line"] != 4.0 || first["column"] != 5.0 || first["endColumn"] != 6.0 {
+		t.Fatalf("unexpected first entry %v", first)
+	}
+	if related := first["related"].([]interface{}); len(related) != 1 {
+		t.Fatalf("expected one related location, but got %v", related)
+	}
+	if _, ok := got[2]["line"]; ok {
+		t.Fatalf("expected unknown line to be omitted, but got %v", got[2])
+	}
+}
+
+func TestWriteJSONWithoutDiagnostics(t *testing.T) {
+	var output bytes.Buffer
+	if err := WriteJSON(&output, nil); err != nil {
+		t.Fatal(err)
+	}
+	if output.String() != "[]\n" {
+		t.Fatalf("expected empty array, but got '%s'", output.String())
+	}
+}
+
+func TestWriteSARIF(t *testing.T) {
+	var output bytes.Buffer
+	if err := WriteSARIF(&output, "smc", emitted); err != nil {
+		t.Fatal(err)
+	}
+
+	var got sarifLog
+	if err := json.Unmarshal(output.Bytes(), &got); err != nil {
+		t.Fatalf("expected valid JSON, but got %v: %s", err, output.String())
+	}
+	if got.Version != "2.1.0" || len(got.Runs) != 1 || got.Runs[0].Tool.Driver.Name != "smc" {
+		t.Fatalf("unexpected log header %s", output.String())
+	}
+
+	run := got.Runs[0]
+	ruleIds := []string{}
+	for _, rule := range run.Tool.Driver.Rules {
+		ruleIds = append(ruleIds, rule.Id)
+	}
+	expectedRuleIds := []string{"DUPLICATE_TRANSITION", "INCONSISTENT_ABSTRACTION", "NO_FSM"}
+	if !reflect.DeepEqual(ruleIds, expectedRuleIds) {
+		t.Fatalf("expected rules %v, but got %v", expectedRuleIds, ruleIds)
+	}
+
+	first := run.Results[0]
+	expectedRegion := &sarifRegion{StartLine: 4, StartColumn: 5, EndLine: 4, EndColumn: 7}
+	if first.Level != "error" || first.RuleIndex != 0 || len(first.Locations) != 1 ||
+		first.Locations[0].PhysicalLocation.ArtifactLocation.Uri != "dir/f.sm" ||
+		!reflect.DeepEqual(first.Locations[0].PhysicalLocation.Region, expectedRegion) ||
+		len(first.RelatedLocations) != 1 {
+		t.Fatalf("unexpected first result %+v", first)
+	}
+	if second := run.Results[1]; second.Level != "warning" || second.Locations[0].PhysicalLocation.Region != nil {
+		t.Fatalf("expected warning without region, but got %+v", second)
+	}
+	if third := run.Results[2]; third.RuleIndex != 2 || len(third.Locations) != 0 {
+		t.Fatalf("expected result without location, but got %+v", third)
+	}
+}
@@ -0,0 +1,54 @@
+package diagnostics
+
+import (
+	"encoding/json"
+	"io"
+)
+
+type jsonLocation struct {
+	File      string `json:"file,omitempty"`
+	Line      int    `json:"line,omitempty"`
+	Column    int    `json:"column,omitempty"`
+	EndLine   int    `json:"endLine,omitempty"`
+	EndColumn int    `json:"endColumn,omitempty"`
+}
+
+type jsonDiagnostic struct {
+	Severity string `json:"severity"`
+	Code     string `json:"code"`
+	Message  string `json:"message"`
+	jsonLocation
+	Related []jsonLocation `json:"related,omitempty"`
+}
+
+func newJSONLocation(location Location) jsonLocation {
+	return jsonLocation{
+		File:      location.File,
+		Line:      location.Line,
+		Column:    location.Column,
+		EndLine:   location.EndLine,
+		EndColumn: location.EndColumn,
+	}
+}
+
+// WriteJSON writes the diagnostics as a JSON array. Unknown positions are
+// omitted rather than written as zero.
+func WriteJSON(w io.Writer, diagnostics []Diagnostic) error {
+	entries := []jsonDiagnostic{}
+	for _, diagnostic := range diagnostics {
+		entry := jsonDiagnostic{
+			Severity:     diagnostic.Severity.String(),
+			Code:         diagnostic.Code,
+			Message:      diagnostic.Message,
+			jsonLocation: newJSONLocation(diagnostic.Location),
+		}
+		for _, related := range diagnostic.Related {
+			entry.Related = append(entry.Related, newJSONLocation(related))
+		}
+		entries = append(entries, entry)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(entries)
+}
@@ -0,0 +1,160 @@
+package diagnostics
+
+import (
+	"encoding/json"
+	"io"
+	"net/url"
+	"path/filepath"
+	"sort"
+)
+
+const (
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion = "2.1.0"
+)
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name  string      `json:"name"`
+	Rules []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	Id               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleId           string          `json:"ruleId"`
+	RuleIndex        int             `json:"ruleIndex"`
+	Level            string          `json:"level"`
+	Message          sarifMessage    `json:"message"`
+	Locations        []sarifLocation `json:"locations,omitempty"`
+	RelatedLocations []sarifLocation `json:"relatedLocations,omitempty"`
+}
+
+type sarifLocation struct {
+	Id               *int                  `json:"id,omitempty"`
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	Uri string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+	EndLine     int `json:"endLine,omitempty"`
+	EndColumn   int `json:"endColumn,omitempty"`
+}
+
+// WriteSARIF writes the diagnostics as a SARIF 2.1.0 log with a single run
+// of the named tool. Every distinct code becomes a rule of that tool.
+func WriteSARIF(w io.Writer, toolName string, diagnostics []Diagnostic) error {
+	rules, ruleIndexes := makeSarifRules(diagnostics)
+	results := []sarifResult{}
+	for _, diagnostic := range diagnostics {
+		results = append(results, makeSarifResult(diagnostic, ruleIndexes[diagnostic.Code]))
+	}
+
+	log := sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs: []sarifRun{{
+			Tool:    sarifTool{Driver: sarifDriver{Name: toolName, Rules: rules}},
+			Results: results,
+		}},
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(log)
+}
+
+func makeSarifRules(diagnostics []Diagnostic) ([]sarifRule, map[string]int) {
+	codes := []string{}
+	seen := make(map[string]bool)
+	for _, diagnostic := range diagnostics {
+		if !seen[diagnostic.Code] {
+			seen[diagnostic.Code] = true
+			codes = append(codes, diagnostic.Code)
+		}
+	}
+	sort.Strings(codes)
+
+	rules := []sarifRule{}
+	ruleIndexes := make(map[string]int)
+	for index, code := range codes {
+		rules = append(rules, sarifRule{Id: code, ShortDescription: sarifMessage{Text: code}})
+		ruleIndexes[code] = index
+	}
+	return rules, ruleIndexes
+}
+
+func makeSarifResult(diagnostic Diagnostic, ruleIndex int) sarifResult {
+	result := sarifResult{
+		RuleId:    diagnostic.Code,
+		RuleIndex: ruleIndex,
+		Level:     diagnostic.Severity.String(),
+		Message:   sarifMessage{Text: diagnostic.Message},
+	}
+	if location, ok := makeSarifLocation(diagnostic.Location); ok {
+		result.Locations = append(result.Locations, location)
+	}
+	for _, related := range diagnostic.Related {
+		if location, ok := makeSarifLocation(related); ok {
+			id := len(result.RelatedLocations)
+			location.Id = &id
+			result.RelatedLocations = append(result.RelatedLocations, location)
+		}
+	}
+	return result
+}
+
+func makeSarifLocation(location Location) (sarifLocation, bool) {
+	if location.File == "" {
+		return sarifLocation{}, false
+	}
+	physicalLocation := sarifPhysicalLocation{
+		ArtifactLocation: sarifArtifactLocation{Uri: makeSarifUri(location.File)},
+	}
+	if location.Line > 0 {
+		physicalLocation.Region = &sarifRegion{StartLine: location.Line, StartColumn: location.Column}
+		if location.EndLine > 0 {
+			physicalLocation.Region.EndLine = location.EndLine
+			// SARIF end columns point just past the region.
+			physicalLocation.Region.EndColumn = location.EndColumn + 1
+		}
+	}
+	return sarifLocation{PhysicalLocation: physicalLocation}, true
+}
+
+func makeSarifUri(file string) string {
+	if filepath.IsAbs(file) {
+		return (&url.URL{Scheme: "file", Path: filepath.ToSlash(file)}).String()
+	}
+	return filepath.ToSlash(file)
+}
@@ -0,0 +1,24 @@
+package generator
+
+import "strings"
+
+type Artifact struct {
+	Name    string
+	Content string
+}
+
+func WriteArtifacts(sink OutputSink, artifacts []Artifact) error {
+	for _, artifact := range artifacts {
+		if err := sink.Write(artifact); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func packagePath(packageName, separator string) string {
+	if packageName == "" {
+		return ""
+	}
+	return strings.ReplaceAll(packageName, separator, "/") + "/"
+}
@@ -0,0 +1,30 @@
+package generator
+
+import (
+	"strings"
+
+	"github.com/larkvincer/dsl-fsm/generator/implementors"
+	nscgenerator "github.com/larkvincer/dsl-fsm/generator/nestedswitchcasegenerator"
+)
+
+type CCodeGenerator struct {
+	cNestedSwitchCaseImplementor *implementors.CNestedSwitchCaseImplementor
+}
+
+func NewCCodeGenerator(cNestedSwitchCaseImplementor *implementors.CNestedSwitchCaseImplementor) *CCodeGenerator {
+	return &CCodeGenerator{
+		cNestedSwitchCaseImplementor: cNestedSwitchCaseImplementor,
+	}
+}
+
+func (cGenerator *CCodeGenerator) GetImplementer() nscgenerator.NSCNodeVisitor {
+	return cGenerator.cNestedSwitchCaseImplementor
+}
+
+func (cGenerator *CCodeGenerator) GetArtifacts(fsmName string) []Artifact {
+	baseName := strings.ToLower(fsmName)
+	return []Artifact{
+		{Name: baseName + ".h", Content: indentBlocks(cGenerator.cNestedSwitchCaseImplementor.Header, "    ")},
+		{Name: baseName + ".c", Content: indentBlocks(cGenerator.cNestedSwitchCaseImplementor.Output, "    ")},
+	}
+}
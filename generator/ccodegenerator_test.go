@@ -0,0 +1,76 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+const cTurnstileMain = `#include <stdio.h>
+#include <string.h>
+#include "twocointurnstile.h"
+
+static void alarmOff(void *context) { (void)context; puts("alarmOff"); }
+static void alarmOn(void *context) { (void)context; puts("alarmOn"); }
+static void lock(void *context) { (void)context; puts("lock"); }
+static void thankyou(void *context) { (void)context; puts("thankyou"); }
+static void unlock(void *context) { (void)context; puts("unlock"); }
+
+static void unhandled(void *context, enum TwoCoinTurnstile_State state, enum TwoCoinTurnstile_Event event) {
+    (void)context;
+    printf("unhandled %s %s\n", TwoCoinTurnstile_state_name(state), TwoCoinTurnstile_event_name(event));
+}
+
+static const struct Turnstile actions = {alarmOff, alarmOn, lock, thankyou, unlock, unhandled};
+
+int main(int argc, char **argv) {
+    struct TwoCoinTurnstile turnstile;
+    int i;
+    TwoCoinTurnstile_init(&turnstile, &actions, NULL);
+    for (i = 1; i < argc; i++) {
+        if (strcmp(argv[i], "Coin") == 0) TwoCoinTurnstile_Coin(&turnstile);
+        if (strcmp(argv[i], "Pass") == 0) TwoCoinTurnstile_Pass(&turnstile);
+        if (strcmp(argv[i], "Reset") == 0) TwoCoinTurnstile_Reset(&turnstile);
+    }
+    puts(TwoCoinTurnstile_state_name(turnstile.state));
+    return 0;
+}
+`
+
+func TestCArtifacts(t *testing.T) {
+	artifacts := generate(t, twoCoinTurnstile, "c", map[string]string{})
+	if len(artifacts) != 2 || artifacts[0].Name != "twocointurnstile.h" || artifacts[1].Name != "twocointurnstile.c" {
+		t.Fatalf("unexpected artifacts %v", artifacts)
+	}
+	for _, artifact := range artifacts {
+		if strings.Contains(artifact.Content, "alloc") {
+			t.Errorf("%s allocates memory:\n%s", artifact.Name, artifact.Content)
+		}
+	}
+	for _, expected := range []string{
+		"enum TwoCoinTurnstile_State {\n    TwoCoinTurnstile_State_Alarming,\n",
+		"struct Turnstile {\n    void (*alarmOff)(void *context);\n",
+		"void TwoCoinTurnstile_Coin(struct TwoCoinTurnstile *fsm);\n",
+	} {
+		if !strings.Contains(artifacts[0].Content, expected) {
+			t.Errorf("expected header to contain %q:\n%s", expected, artifacts[0].Content)
+		}
+	}
+	if !strings.Contains(artifacts[1].Content, "static void handle_event(struct TwoCoinTurnstile *fsm, enum TwoCoinTurnstile_Event event) {\n") {
+		t.Errorf("expected handle_event in source:\n%s", artifacts[1].Content)
+	}
+}
+
+func TestCArtifactsCompileAndRun(t *testing.T) {
+	cc := lookPath(t, "cc")
+	directory := t.TempDir()
+	artifacts := generate(t, twoCoinTurnstile, "c", map[string]string{})
+	artifacts = append(artifacts, Artifact{Name: "main.c", Content: cTurnstileMain})
+	writeArtifacts(t, directory, artifacts)
+
+	run(t, directory, cc, "-std=c99", "-Wall", "-Wextra", "-pedantic", "-Werror",
+		"-o", "turnstile", "main.c", "twocointurnstile.c")
+	output := run(t, directory, directory+"/turnstile", twoCoinTurnstileEvents...)
+	if output != twoCoinTurnstileTrace {
+		t.Fatalf("expected\n%s\nbut got\n%s", twoCoinTurnstileTrace, output)
+	}
+}
@@ -1,13 +1,24 @@
 package generator
 
 import (
+	"fmt"
+
 	nscgenerator "github.com/larkvincer/dsl-fsm/generator/nestedswitchcasegenerator"
 	"github.com/larkvincer/dsl-fsm/optimizer"
 )
 
 type LanguageCodeGenerator interface {
 	GetImplementer() nscgenerator.NSCNodeVisitor
-	WriteFiles()
+	GetArtifacts(fsmName string) []Artifact
+}
+
+// MemberGenerator is implemented by the LanguageCodeGenerators and
+// TableLanguageCodeGenerators that make the events and the actions methods
+// of the one generated class, where they must not collide with each other
+// or with the members that class always declares.
+type MemberGenerator interface {
+	MemberName(name string) string
+	FixedMembers(className string) map[string]bool
 }
 
 type CodeGenerator struct {
@@ -25,9 +36,54 @@ func NewCodeGenerator(
 	}
 }
 
-func (cg *CodeGenerator) Generate() {
+func (cg *CodeGenerator) Generate() ([]Artifact, error) {
+	if err := checkInitialState(cg.optimizedStateMachine); err != nil {
+		return nil, err
+	}
+	if memberGenerator, ok := cg.languageCodeGenerator.(MemberGenerator); ok {
+		osm := cg.optimizedStateMachine
+		if err := checkMembers(osm.Header.Fsm, osm.Events, osm.Actions, memberGenerator); err != nil {
+			return nil, err
+		}
+	}
 	implementor := cg.languageCodeGenerator.GetImplementer()
 	nscGenerator := nscgenerator.NSCGenerator{}
 	nscGenerator.Generate(cg.optimizedStateMachine).Accept(implementor)
-	cg.languageCodeGenerator.WriteFiles()
+	return cg.languageCodeGenerator.GetArtifacts(cg.optimizedStateMachine.Header.Fsm), nil
+}
+
+// checkInitialState rejects an abstract initial state. Generated code only
+// has the concrete states of osm.States, so a machine could not start in
+// it.
+func checkInitialState(osm *optimizer.OptimizedStateMachine) error {
+	for _, state := range osm.States {
+		if state == osm.Header.Initial {
+			return nil
+		}
+	}
+	return fmt.Errorf("initial state %s of %s is abstract", osm.Header.Initial, osm.Header.Fsm)
+}
+
+// checkMembers rejects events and actions whose methods would share a name,
+// like event lock and action Lock in a language that capitalizes methods.
+func checkMembers(fsm string, events, actions []string, memberGenerator MemberGenerator) error {
+	fixedMembers := memberGenerator.FixedMembers(fsm)
+	members := map[string]string{}
+	for _, kind := range []struct {
+		name  string
+		names []string
+	}{{"event", events}, {"action", actions}} {
+		for _, name := range kind.names {
+			member := memberGenerator.MemberName(name)
+			if fixedMembers[member] {
+				return fmt.Errorf("%s %s of %s collides with the %s member of the generated class",
+					kind.name, name, fsm, member)
+			}
+			if other, ok := members[member]; ok {
+				return fmt.Errorf("%s and %s %s of %s both become the method %s", other, kind.name, name, fsm, member)
+			}
+			members[member] = kind.name + " " + name
+		}
+	}
+	return nil
 }
@@ -0,0 +1,177 @@
+package generator
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/larkvincer/dsl-fsm/optimizer"
+)
+
+// codeStrategies are the languages that generate state machine code, each
+// with the flags of its strategy, named like "go" or "go/table".
+func codeStrategies() map[string]map[string]string {
+	strategies := map[string]map[string]string{}
+	for language := range languageCodeGenerators {
+		strategies[language] = map[string]string{}
+	}
+	for language := range tableCodeGenerators {
+		strategies[language+"/table"] = map[string]string{"strategy": "table"}
+	}
+	for language := range statePatternGenerators {
+		strategies[language+"/state"] = map[string]string{"strategy": "state"}
+	}
+	return strategies
+}
+
+// allStrategies adds every other generator to codeStrategies; the template
+// generator renders a template that lists the transitions.
+func allStrategies(t *testing.T) map[string]map[string]string {
+	t.Helper()
+	strategies := codeStrategies()
+	templates := writeTemplates(t, map[string]string{
+		"{{snake .Header.Fsm}}.txt.tmpl": "{{range .Transitions}}{{.CurrentState}}:" +
+			"{{range .SubTransitions}} {{.Event}}->{{.NextState}}{{end}}\n{{end}}",
+	})
+	for language := range generators {
+		strategies[language] = map[string]string{}
+	}
+	strategies["template"]["templates"] = templates
+	return strategies
+}
+
+func sortedStrategies(strategies map[string]map[string]string) []string {
+	names := []string{}
+	for name := range strategies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func strategyLanguage(strategy string) string {
+	return strings.TrimSuffix(strings.TrimSuffix(strategy, "/table"), "/state")
+}
+
+func TestCodeGeneratorsRejectAbstractInitialState(t *testing.T) {
+	strategies := codeStrategies()
+	names := sortedStrategies(strategies)
+
+	for name, source := range map[string]string{
+		"no concrete states": "FSM: f\nActions: a\nInitial: b\n{(b) <a * * *}",
+		"concrete states":    "FSM: f\nActions: a\nInitial: b\n{\n  (b) e c {}\n  c : b f c {}\n}\n",
+	} {
+		for _, strategy := range names {
+			t.Run(name+"/"+strategy, func(t *testing.T) {
+				err := generateError(t, source, strategyLanguage(strategy), strategies[strategy])
+				if err == nil || err.Error() != "initial state b of f is abstract" {
+					t.Fatalf("expected an error for the abstract initial state, got %v", err)
+				}
+			})
+		}
+	}
+}
+
+// trickyInputs are valid state machines that some generators can not
+// express. Every generator must either generate them or return the error
+// listed for it.
+var trickyInputs = map[string]struct {
+	source string
+	errors map[string]string
+}{
+	"diamond hierarchy": {
+		"FSM: f\nInitial: i\n{\n" +
+			"  (b) <eb >xb be j {}\n" +
+			"  (b1) : b b1e i a1\n" +
+			"  (b2) : b <e2 b2e j {}\n" +
+			"  i : b1 : b2 {\n    e j a\n    be * a\n  }\n" +
+			"  j : b1 e i {}\n" +
+			"}\n",
+		map[string]string{"scxml": "SCXML cannot express state i with several superstates"},
+	},
+	"superstate with only entry and exit actions": {
+		"FSM: f\nInitial: i\n{\n  (b) <en >ex {}\n  i : b e j {}\n  j e i {}\n}\n",
+		nil,
+	},
+	"self transitions only": {
+		"FSM: f\nInitial: i\n{\n  i {\n    e * a\n    g * {}\n  }\n}\n",
+		nil,
+	},
+	"state and event of the same name": {
+		"FSM: f\nInitial: Open\n{\n  Open Close Close {}\n  Close Open Open {}\n}\n",
+		nil,
+	},
+	"event and action of the same name": {
+		"FSM: f\nInitial: i\n{\n  i lock j lock\n  j unlock i unlock\n}\n",
+		withError("event lock and action lock of f both become the method lock",
+			"cpp", "java", "java/state", "java/table", "kotlin", "python", "typescript",
+		).with("csharp", "event lock and action lock of f both become the method Lock"),
+	},
+	"abstract initial state": {
+		"FSM: f\nInitial: b\n{\n  (b) e c {}\n  c : b f c {}\n}\n",
+		withError("initial state b of f is abstract",
+			"c", "cpp", "csharp", "elixir", "erlang", "go", "go/table", "java", "java/state", "java/table",
+			"kotlin", "python", "rust", "sql", "typescript", "verilog", "vhdl",
+		),
+	},
+}
+
+type strategyErrors map[string]string
+
+func withError(message string, strategies ...string) strategyErrors {
+	errors := strategyErrors{}
+	for _, strategy := range strategies {
+		errors[strategy] = message
+	}
+	return errors
+}
+
+func (errors strategyErrors) with(strategy, message string) strategyErrors {
+	errors[strategy] = message
+	return errors
+}
+
+func TestGeneratorsHandleTrickyInputs(t *testing.T) {
+	strategies := allStrategies(t)
+	for name, input := range trickyInputs {
+		for _, strategy := range sortedStrategies(strategies) {
+			t.Run(name+"/"+strategy, func(t *testing.T) {
+				codeGenerator, err := NewGenerator(strategyLanguage(strategy), strategies[strategy])
+				if err != nil {
+					t.Fatal(err)
+				}
+				semanticStateMachine := analyze(t, input.source)
+				machine := &StateMachine{
+					Semantic:  semanticStateMachine,
+					Optimized: optimizer.Optimize(*semanticStateMachine),
+				}
+				artifacts, err := codeGenerator.Generate(machine)
+				if message, ok := input.errors[strategy]; ok {
+					if err == nil || err.Error() != message {
+						t.Fatalf("expected %q, got %v", message, err)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if len(artifacts) == 0 {
+					t.Fatal("expected artifacts")
+				}
+				names := map[string]bool{}
+				for _, artifact := range artifacts {
+					if artifact.Name == "" || artifact.Content == "" || names[artifact.Name] {
+						t.Fatalf("expected named, non-empty artifacts with distinct names, got %v", artifacts)
+					}
+					names[artifact.Name] = true
+				}
+				again, err := codeGenerator.Generate(machine)
+				if err != nil || !reflect.DeepEqual(again, artifacts) {
+					t.Fatalf("expected generating again to give the same artifacts, got %v", err)
+				}
+			})
+		}
+	}
+}
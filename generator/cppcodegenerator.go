@@ -0,0 +1,42 @@
+package generator
+
+import (
+	"strings"
+
+	"github.com/larkvincer/dsl-fsm/generator/implementors"
+	nscgenerator "github.com/larkvincer/dsl-fsm/generator/nestedswitchcasegenerator"
+)
+
+type CppCodeGenerator struct {
+	cppNestedSwitchCaseImplementor *implementors.CppNestedSwitchCaseImplementor
+}
+
+func NewCppCodeGenerator(cppNestedSwitchCaseImplementor *implementors.CppNestedSwitchCaseImplementor) *CppCodeGenerator {
+	return &CppCodeGenerator{
+		cppNestedSwitchCaseImplementor: cppNestedSwitchCaseImplementor,
+	}
+}
+
+func (cppGenerator *CppCodeGenerator) GetImplementer() nscgenerator.NSCNodeVisitor {
+	return cppGenerator.cppNestedSwitchCaseImplementor
+}
+
+func (cppGenerator *CppCodeGenerator) GetArtifacts(fsmName string) []Artifact {
+	baseName := strings.ToLower(fsmName)
+	return []Artifact{
+		{Name: baseName + ".hpp", Content: indentBlocks(cppGenerator.cppNestedSwitchCaseImplementor.Header, "    ")},
+		{Name: baseName + ".cpp", Content: indentBlocks(cppGenerator.cppNestedSwitchCaseImplementor.Output, "    ")},
+	}
+}
+
+func (cppGenerator *CppCodeGenerator) MemberName(name string) string {
+	return name
+}
+
+// FixedMembers are the type aliases and the members of the generated class
+// besides the events and actions, including derived() of the crtp
+// dispatch. A member can not take the name of the class either.
+func (cppGenerator *CppCodeGenerator) FixedMembers(className string) map[string]bool {
+	return setOf(className, "State", "Event", "getState", "state", "setState", "derived", "handleEvent",
+		"unhandledTransition")
+}
@@ -0,0 +1,146 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+const cppVirtualTurnstileMain = `#include <cstring>
+#include <iostream>
+#include "twocointurnstile.hpp"
+
+using namespace turnstile;
+
+class Printer : public TwoCoinTurnstile {
+public:
+    void alarmOff() override { std::cout << "alarmOff\n"; }
+    void alarmOn() override { std::cout << "alarmOn\n"; }
+    void lock() override { std::cout << "lock\n"; }
+    void thankyou() override { std::cout << "thankyou\n"; }
+    void unlock() override { std::cout << "unlock\n"; }
+
+protected:
+    void unhandledTransition(State state, Event event) override {
+        std::cout << "unhandled " << toString(state) << " " << toString(event) << "\n";
+    }
+};
+
+int main(int argc, char **argv) {
+    Printer turnstile;
+    for (int i = 1; i < argc; i++) {
+        if (std::strcmp(argv[i], "Coin") == 0) turnstile.Coin();
+        if (std::strcmp(argv[i], "Pass") == 0) turnstile.Pass();
+        if (std::strcmp(argv[i], "Reset") == 0) turnstile.Reset();
+    }
+    std::cout << toString(turnstile.getState()) << "\n";
+    return 0;
+}
+`
+
+const cppCrtpTurnstileMain = `#include <cstring>
+#include <iostream>
+#include "twocointurnstile.hpp"
+
+class Printer : public TwoCoinTurnstile<Printer> {
+    friend class TwoCoinTurnstile<Printer>;
+
+    void alarmOff() { std::cout << "alarmOff\n"; }
+    void alarmOn() { std::cout << "alarmOn\n"; }
+    void lock() { std::cout << "lock\n"; }
+    void thankyou() { std::cout << "thankyou\n"; }
+    void unlock() { std::cout << "unlock\n"; }
+    void unhandledTransition(State state, Event event) {
+        std::cout << "unhandled " << toString(state) << " " << toString(event) << "\n";
+    }
+};
+
+int main(int argc, char **argv) {
+    Printer turnstile;
+    for (int i = 1; i < argc; i++) {
+        if (std::strcmp(argv[i], "Coin") == 0) turnstile.Coin();
+        if (std::strcmp(argv[i], "Pass") == 0) turnstile.Pass();
+        if (std::strcmp(argv[i], "Reset") == 0) turnstile.Reset();
+    }
+    std::cout << toString(turnstile.getState()) << "\n";
+    return 0;
+}
+`
+
+func TestCppVirtualArtifacts(t *testing.T) {
+	artifacts := generate(t, twoCoinTurnstile, "cpp", map[string]string{"namespace": "turnstile"})
+	if len(artifacts) != 2 || artifacts[0].Name != "twocointurnstile.hpp" || artifacts[1].Name != "twocointurnstile.cpp" {
+		t.Fatalf("unexpected artifacts %v", artifacts)
+	}
+	for _, expected := range []string{
+		"    enum class TwoCoinTurnstileState {\n        Alarming,\n",
+		"    class Turnstile {\n    public:\n        virtual ~Turnstile() = default;\n\n        virtual void alarmOff() = 0;\n",
+		"    class TwoCoinTurnstile : public Turnstile {\n",
+		"        virtual void unhandledTransition(State state, Event event) = 0;\n",
+	} {
+		if !strings.Contains(artifacts[0].Content, expected) {
+			t.Errorf("expected header to contain %q:\n%s", expected, artifacts[0].Content)
+		}
+	}
+	if !strings.Contains(artifacts[1].Content, "    void TwoCoinTurnstile::handleEvent(Event event) {\n") {
+		t.Errorf("expected handleEvent in source:\n%s", artifacts[1].Content)
+	}
+}
+
+func TestCppCrtpArtifacts(t *testing.T) {
+	artifacts := generate(t, twoCoinTurnstile, "cpp", map[string]string{"dispatch": "crtp"})
+	for _, expected := range []string{
+		"template <typename Derived>\nclass TwoCoinTurnstile {\n",
+		"    void Coin() { handleEvent(Event::Coin); }\n",
+		"                        derived().alarmOff();\n",
+	} {
+		if !strings.Contains(artifacts[0].Content, expected) {
+			t.Errorf("expected header to contain %q:\n%s", expected, artifacts[0].Content)
+		}
+	}
+	if strings.Contains(artifacts[0].Content, "virtual") || strings.Contains(artifacts[1].Content, "handleEvent") {
+		t.Errorf("expected the whole machine in the header without virtual calls:\n%s", artifacts[0].Content)
+	}
+}
+
+func TestCppRejectsCollidingMembers(t *testing.T) {
+	for name, test := range map[string]struct{ source, message string }{
+		"event and action": {"FSM: f\nInitial: a\n{\n  a lock a lock\n}\n",
+			"event lock and action lock of f both become the method lock"},
+		"event and type alias": {"FSM: f\nInitial: a\n{\n  a State a {}\n}\n",
+			"event State of f collides with the State member of the generated class"},
+		"action and crtp member": {"FSM: f\nInitial: a\n{\n  a e a derived\n}\n",
+			"action derived of f collides with the derived member of the generated class"},
+		"event and class": {"FSM: Door\nInitial: a\n{\n  a Door a {}\n}\n",
+			"event Door of Door collides with the Door member of the generated class"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := generateError(t, test.source, "cpp", map[string]string{})
+			if err == nil || err.Error() != test.message {
+				t.Fatalf("expected %q, got %v", test.message, err)
+			}
+		})
+	}
+}
+
+func TestCppArtifactsCompileAndRun(t *testing.T) {
+	cxx := lookPath(t, "c++")
+	for dispatch, main := range map[string]string{"virtual": cppVirtualTurnstileMain, "crtp": cppCrtpTurnstileMain} {
+		t.Run(dispatch, func(t *testing.T) {
+			directory := t.TempDir()
+			flags := map[string]string{"dispatch": dispatch}
+			if dispatch == "virtual" {
+				flags["namespace"] = "turnstile"
+			}
+			artifacts := generate(t, twoCoinTurnstile, "cpp", flags)
+			artifacts = append(artifacts, Artifact{Name: "main.cpp", Content: main})
+			writeArtifacts(t, directory, artifacts)
+
+			run(t, directory, cxx, "-std=c++11", "-Wall", "-Wextra", "-pedantic", "-Werror",
+				"-o", "turnstile", "main.cpp", "twocointurnstile.cpp")
+			output := run(t, directory, directory+"/turnstile", twoCoinTurnstileEvents...)
+			if output != twoCoinTurnstileTrace {
+				t.Fatalf("expected\n%s\nbut got\n%s", twoCoinTurnstileTrace, output)
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package generator
+
+import (
+	"github.com/larkvincer/dsl-fsm/generator/implementors"
+	nscgenerator "github.com/larkvincer/dsl-fsm/generator/nestedswitchcasegenerator"
+)
+
+type CSharpCodeGenerator struct {
+	cSharpNestedSwitchCaseImplementor *implementors.CSharpNestedSwitchCaseImplementor
+}
+
+func NewCSharpCodeGenerator(
+	cSharpNestedSwitchCaseImplementor *implementors.CSharpNestedSwitchCaseImplementor,
+) *CSharpCodeGenerator {
+	return &CSharpCodeGenerator{
+		cSharpNestedSwitchCaseImplementor: cSharpNestedSwitchCaseImplementor,
+	}
+}
+
+func (csharpGenerator *CSharpCodeGenerator) GetImplementer() nscgenerator.NSCNodeVisitor {
+	return csharpGenerator.cSharpNestedSwitchCaseImplementor
+}
+
+func (csharpGenerator *CSharpCodeGenerator) GetArtifacts(fsmName string) []Artifact {
+	return []Artifact{{
+		Name:    fsmName + ".cs",
+		Content: indentBlocks(csharpGenerator.cSharpNestedSwitchCaseImplementor.Output, "    "),
+	}}
+}
+
+func (csharpGenerator *CSharpCodeGenerator) MemberName(name string) string {
+	return implementors.Capitalize(name)
+}
+
+// FixedMembers are the nested enums and the members of the generated
+// class besides the events and actions. A member can not take the name of
+// the class either.
+func (csharpGenerator *CSharpCodeGenerator) FixedMembers(className string) map[string]bool {
+	return setOf(className, "State", "Event", "CurrentState", "SetState", "HandleEvent", "UnhandledTransition")
+}
@@ -0,0 +1,108 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+const csharpTurnstileProject = `<Project Sdk="Microsoft.NET.Sdk">
+  <PropertyGroup>
+    <OutputType>Exe</OutputType>
+    <TargetFramework>net8.0</TargetFramework>
+    <TreatWarningsAsErrors>true</TreatWarningsAsErrors>
+  </PropertyGroup>
+</Project>
+`
+
+const csharpTurnstileMain = `using System;
+using Firsttry;
+
+class Turnstile : TwoCoinTurnstile
+{
+    protected override void AlarmOff() => Console.WriteLine("alarmOff");
+    protected override void AlarmOn() => Console.WriteLine("alarmOn");
+    protected override void Lock() => Console.WriteLine("lock");
+    protected override void Thankyou() => Console.WriteLine("thankyou");
+    protected override void Unlock() => Console.WriteLine("unlock");
+    protected override void UnhandledTransition(string state, string evt) =>
+        Console.WriteLine("unhandled " + state + " " + evt);
+
+    static void Main(string[] args)
+    {
+        var turnstile = new Turnstile();
+        foreach (var evt in args)
+        {
+            typeof(TwoCoinTurnstile).GetMethod(evt).Invoke(turnstile, null);
+        }
+        Console.WriteLine(turnstile.CurrentState);
+    }
+}
+`
+
+func TestCSharpArtifact(t *testing.T) {
+	artifacts := generate(t, twoCoinTurnstile, "csharp", map[string]string{"namespace": "Firsttry"})
+	if len(artifacts) != 1 || artifacts[0].Name != "TwoCoinTurnstile.cs" {
+		t.Fatalf("unexpected artifacts %v", artifacts)
+	}
+	content := artifacts[0].Content
+	for _, expected := range []string{
+		"namespace Firsttry\n{\n    public abstract partial class TwoCoinTurnstile\n    {\n",
+		"        public enum State\n        {\n            Alarming,\n",
+		"        public void Coin()\n        {\n            HandleEvent(Event.Coin);\n        }\n",
+		"        protected virtual void UnhandledTransition(string state, string evt)\n",
+		"        protected abstract void AlarmOff();\n",
+	} {
+		if !strings.Contains(content, expected) {
+			t.Errorf("expected generated code to contain %q:\n%s", expected, content)
+		}
+	}
+}
+
+func TestCSharpWithoutNamespace(t *testing.T) {
+	artifacts := generate(t, twoCoinTurnstile, "csharp", map[string]string{})
+	if !strings.HasPrefix(artifacts[0].Content, "// Generated by smc. Do not edit.\npublic abstract partial class TwoCoinTurnstile\n{\n") {
+		t.Fatalf("expected no namespace:\n%s", artifacts[0].Content)
+	}
+}
+
+func TestCSharpRejectsCollidingMembers(t *testing.T) {
+	for name, test := range map[string]struct{ source, message string }{
+		"event and action": {"FSM: f\nInitial: a\n{\n  a lock a Lock\n}\n",
+			"event lock and action Lock of f both become the method Lock"},
+		"events": {"FSM: f\nInitial: a\n{\n  a {\n    coin a {}\n    Coin a {}\n  }\n}\n",
+			"event Coin and event coin of f both become the method Coin"},
+		"event and nested enum": {"FSM: f\nInitial: a\n{\n  a state a {}\n}\n",
+			"event state of f collides with the State member of the generated class"},
+		"action and fixed member": {"FSM: f\nInitial: a\n{\n  a e a handleEvent\n}\n",
+			"action handleEvent of f collides with the HandleEvent member of the generated class"},
+		"action and class": {"FSM: Door\nInitial: a\n{\n  a e a door\n}\n",
+			"action door of Door collides with the Door member of the generated class"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := generateError(t, test.source, "csharp", map[string]string{})
+			if err == nil || err.Error() != test.message {
+				t.Fatalf("expected %q, got %v", test.message, err)
+			}
+		})
+	}
+}
+
+func TestCSharpArtifactCompilesAndRuns(t *testing.T) {
+	if testing.Short() {
+		t.Skip("building a .NET project is slow")
+	}
+	dotnet := lookPath(t, "dotnet")
+	directory := t.TempDir()
+	artifacts := generate(t, twoCoinTurnstile, "csharp", map[string]string{"namespace": "Firsttry"})
+	artifacts = append(artifacts,
+		Artifact{Name: "Turnstile.csproj", Content: csharpTurnstileProject},
+		Artifact{Name: "Program.cs", Content: csharpTurnstileMain},
+	)
+	writeArtifacts(t, directory, artifacts)
+
+	run(t, directory, dotnet, "build", "--nologo", "-v", "q", "-o", "out")
+	output := run(t, directory, dotnet, append([]string{"out/Turnstile.dll"}, twoCoinTurnstileEvents...)...)
+	if output != twoCoinTurnstileTrace {
+		t.Fatalf("expected\n%s\nbut got\n%s", twoCoinTurnstileTrace, output)
+	}
+}
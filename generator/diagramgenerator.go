@@ -0,0 +1,148 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/larkvincer/dsl-fsm/generator/implementors"
+	"github.com/larkvincer/dsl-fsm/semanticanalyzer"
+)
+
+type DiagramSyntax int
+
+const (
+	Mermaid DiagramSyntax = iota
+	PlantUML
+)
+
+// DiagramGenerator emits a Mermaid stateDiagram-v2 or a PlantUML state
+// diagram for documentation. Superstates become composite states around
+// their substates; a state with several superstates is drawn in the first
+// one by name, and the others get a description like "abstract superstate
+// of i" so that they do not read as ordinary states. Entry and exit actions
+// become state descriptions as well. Both
+// languages tie a state to the block it is first mentioned in, so states
+// are declared before any transition, and every transition is written in
+// the innermost composite state that holds both of its ends.
+type DiagramGenerator struct {
+	syntax    DiagramSyntax
+	output    strings.Builder
+	children  map[*semanticanalyzer.SemanticState][]*semanticanalyzer.SemanticState
+	parents   map[*semanticanalyzer.SemanticState]*semanticanalyzer.SemanticState
+	substates map[*semanticanalyzer.SemanticState][]*semanticanalyzer.SemanticState
+	scoped    map[*semanticanalyzer.SemanticState][]string
+}
+
+func NewDiagramGenerator(syntax DiagramSyntax) *DiagramGenerator {
+	return &DiagramGenerator{syntax: syntax}
+}
+
+func (diagramGenerator *DiagramGenerator) Generate(machine *StateMachine) ([]Artifact, error) {
+	ssm := machine.Semantic
+	out := &diagramGenerator.output
+	out.Reset()
+	states := sortedSemanticStates(ssm)
+	var roots []*semanticanalyzer.SemanticState
+	roots, diagramGenerator.children = stateTree(states)
+	diagramGenerator.parents = parentsOf(diagramGenerator.children)
+	diagramGenerator.substates = substatesOf(states)
+	diagramGenerator.scoped = map[*semanticanalyzer.SemanticState][]string{
+		nil: {"[*] --> " + ssm.InitialState.Name},
+	}
+	for _, state := range states {
+		for _, transition := range state.Transitions {
+			scope := diagramGenerator.commonScope(state, transition.NextState)
+			diagramGenerator.scoped[scope] = append(diagramGenerator.scoped[scope], fmt.Sprintf("%s --> %s : %s",
+				state.Name, transition.NextState.Name, edgeLabel(transition.Event, transition.Action)))
+		}
+	}
+
+	name := implementors.SnakeCase(ssm.FsmName)
+	extension := ".mmd"
+	if diagramGenerator.syntax == PlantUML {
+		extension = ".puml"
+		fmt.Fprintf(out, "@startuml\n")
+		fmt.Fprintf(out, "title %s\n", ssm.FsmName)
+		fmt.Fprintf(out, "hide empty description\n")
+	} else {
+		fmt.Fprintf(out, "---\ntitle: %s\n---\n", ssm.FsmName)
+		fmt.Fprintf(out, "stateDiagram-v2\n")
+	}
+	indent := "    "
+	if diagramGenerator.syntax == PlantUML {
+		indent = ""
+	}
+	diagramGenerator.writeScope(nil, roots, indent)
+	if diagramGenerator.syntax == PlantUML {
+		fmt.Fprintf(out, "@enduml\n")
+	}
+	return []Artifact{{Name: name + extension, Content: out.String()}}, nil
+}
+
+// writeScope writes the states of a composite state, or of the whole diagram
+// when scope is nil, followed by the transitions that belong to it.
+func (diagramGenerator *DiagramGenerator) writeScope(
+	scope *semanticanalyzer.SemanticState,
+	states []*semanticanalyzer.SemanticState,
+	indent string,
+) {
+	out := &diagramGenerator.output
+	for _, state := range states {
+		if children := diagramGenerator.children[state]; len(children) > 0 {
+			fmt.Fprintf(out, "%sstate %s {\n", indent, state.Name)
+			diagramGenerator.writeScope(state, children, indent+"    ")
+			fmt.Fprintf(out, "%s}\n", indent)
+		} else if diagramGenerator.syntax == PlantUML {
+			fmt.Fprintf(out, "%sstate %s\n", indent, state.Name)
+		} else {
+			fmt.Fprintf(out, "%s%s\n", indent, state.Name)
+		}
+		for _, action := range state.EntryActions {
+			fmt.Fprintf(out, "%s%s : entry / %s\n", indent, state.Name, action)
+		}
+		for _, action := range state.ExitActions {
+			fmt.Fprintf(out, "%s%s : exit / %s\n", indent, state.Name, action)
+		}
+		if role := diagramGenerator.role(state); role != "" {
+			fmt.Fprintf(out, "%s%s : %s\n", indent, state.Name, role)
+		}
+	}
+	for _, transition := range diagramGenerator.scoped[scope] {
+		fmt.Fprintf(out, "%s%s\n", indent, transition)
+	}
+}
+
+// role names the substates of state drawn in other superstates, which its
+// nesting cannot show.
+func (diagramGenerator *DiagramGenerator) role(state *semanticanalyzer.SemanticState) string {
+	elsewhere := substatesDrawnElsewhere(state, diagramGenerator.substates, diagramGenerator.parents)
+	if len(elsewhere) == 0 {
+		return ""
+	}
+	role := "superstate of " + strings.Join(elsewhere, ", ")
+	if state.AbstractState {
+		role = "abstract " + role
+	}
+	return role
+}
+
+// commonScope returns the innermost composite state that holds both from
+// and to, or nil when only the diagram itself does.
+func (diagramGenerator *DiagramGenerator) commonScope(from, to *semanticanalyzer.SemanticState) *semanticanalyzer.SemanticState {
+	fromScopes := diagramGenerator.enclosingScopes(from)
+	toScopes := diagramGenerator.enclosingScopes(to)
+	var scope *semanticanalyzer.SemanticState
+	for i := 0; i < len(fromScopes) && i < len(toScopes) && fromScopes[i] == toScopes[i]; i++ {
+		scope = fromScopes[i]
+	}
+	return scope
+}
+
+// enclosingScopes lists the composite states around state, outermost first.
+func (diagramGenerator *DiagramGenerator) enclosingScopes(state *semanticanalyzer.SemanticState) []*semanticanalyzer.SemanticState {
+	scopes := []*semanticanalyzer.SemanticState{}
+	for parent := diagramGenerator.parents[state]; parent != nil; parent = diagramGenerator.parents[parent] {
+		scopes = append([]*semanticanalyzer.SemanticState{parent}, scopes...)
+	}
+	return scopes
+}
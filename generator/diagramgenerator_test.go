@@ -0,0 +1,88 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMermaidGenerator(t *testing.T) {
+	artifacts := generate(t, twoCoinTurnstile, "mermaid", map[string]string{})
+	if len(artifacts) != 1 || artifacts[0].Name != "two_coin_turnstile.mmd" {
+		t.Fatalf("unexpected artifacts %v", artifacts)
+	}
+	expected := "---\n" +
+		"title: TwoCoinTurnstile\n" +
+		"---\n" +
+		"stateDiagram-v2\n" +
+		"    state Base {\n" +
+		"        Alarming\n" +
+		"        Alarming : entry / alarmOn\n" +
+		"        Alarming : exit / alarmOff\n" +
+		"        FirstCoin\n" +
+		"        Locked\n" +
+		"        Unlocked\n" +
+		"        FirstCoin --> Alarming : Pass\n" +
+		"        FirstCoin --> Unlocked : Coin / unlock\n" +
+		"        Locked --> Alarming : Pass\n" +
+		"        Locked --> FirstCoin : Coin\n" +
+		"        Unlocked --> Locked : Pass / lock\n" +
+		"        Unlocked --> Unlocked : Coin / thankyou\n" +
+		"    }\n" +
+		"    [*] --> Locked\n" +
+		"    Base --> Locked : Reset / lock\n"
+	if artifacts[0].Content != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, artifacts[0].Content)
+	}
+}
+
+func TestPlantUMLGenerator(t *testing.T) {
+	artifacts := generate(t, multipleSuperStates, "plantuml", map[string]string{})
+	if len(artifacts) != 1 || artifacts[0].Name != "machine.puml" {
+		t.Fatalf("unexpected artifacts %v", artifacts)
+	}
+	content := artifacts[0].Content
+	for _, expected := range []string{
+		"@startuml\ntitle Machine\nhide empty description\n",
+		"state b1 {\n" +
+			"    state b2 {\n" +
+			"        state i\n" +
+			"        i : entry / ei\n" +
+			"        i : exit / xi\n" +
+			"    }\n" +
+			"    b2 : entry / e2\n" +
+			"    b2 : exit / x2\n" +
+			"    state j\n" +
+			"    b2 --> i : s\n" +
+			"    i --> j : t / d\n" +
+			"    j --> i : t\n" +
+			"}\n" +
+			"b1 : entry / e1\n" +
+			"b1 : exit / x1\n" +
+			"state b3\n" +
+			"b3 : entry / e3\n" +
+			"b3 : exit / x3\n" +
+			"b3 : abstract superstate of i\n",
+		"[*] --> i\nb1 --> i : r / a\nb3 --> j : u / c\n@enduml\n",
+	} {
+		if !strings.Contains(content, expected) {
+			t.Errorf("expected generated diagram to contain %q:\n%s", expected, content)
+		}
+	}
+}
+
+func TestMermaidDescribesSuperStatesOfStatesDrawnElsewhere(t *testing.T) {
+	source := "FSM: f\nInitial: a\n{\n  (s) e a {}\n  (z) g a {}\n  t : s f a {}\n  a : s : y : z f t {}\n  y h a {}\n}\n"
+	content := generate(t, source, "mermaid", map[string]string{})[0].Content
+	for _, expected := range []string{
+		"    state s {\n        a\n        t\n",
+		"    y\n    y : superstate of a\n",
+		"    z\n    z : abstract superstate of a\n",
+	} {
+		if !strings.Contains(content, expected) {
+			t.Errorf("expected generated diagram to contain %q:\n%s", expected, content)
+		}
+	}
+	if strings.Contains(content, "s : ") {
+		t.Errorf("expected no description for the composite state s:\n%s", content)
+	}
+}
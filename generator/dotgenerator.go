@@ -0,0 +1,264 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/larkvincer/dsl-fsm/generator/implementors"
+	"github.com/larkvincer/dsl-fsm/optimizer"
+	"github.com/larkvincer/dsl-fsm/semanticanalyzer"
+)
+
+// DotGenerator draws the state machine as a Graphviz digraph. By default it
+// keeps the hierarchy: every superstate and every abstract state becomes a
+// cluster, and a state is drawn inside the cluster of its first superstate
+// by name. A superstate whose substates are all drawn elsewhere, like the
+// second superstate of a state, is a dashed cluster with no members; its
+// label names the substates. Transitions of a superstate leave its
+// cluster's border when they lead out of it. Entry and exit actions are
+// listed in the state labels, edges are labelled "event / actions" and a
+// point marks the initial state. The flag mode=flattened draws the
+// optimized machine instead, where every concrete state lists all the
+// transitions it inherits and the edges carry the entry and exit actions.
+type DotGenerator struct {
+	flattened bool
+	output    strings.Builder
+	children  map[*semanticanalyzer.SemanticState][]*semanticanalyzer.SemanticState
+	parents   map[*semanticanalyzer.SemanticState]*semanticanalyzer.SemanticState
+	substates map[*semanticanalyzer.SemanticState][]*semanticanalyzer.SemanticState
+}
+
+func NewDotGenerator(flags map[string]string) (*DotGenerator, error) {
+	dotGenerator := &DotGenerator{}
+	switch mode := flags["mode"]; mode {
+	case "", "hierarchical":
+	case "flattened":
+		dotGenerator.flattened = true
+	default:
+		return nil, fmt.Errorf("unknown DOT mode %q, expected hierarchical or flattened", mode)
+	}
+	return dotGenerator, nil
+}
+
+func (dotGenerator *DotGenerator) Generate(machine *StateMachine) ([]Artifact, error) {
+	dotGenerator.output.Reset()
+	name := machine.Optimized.Header.Fsm
+	if dotGenerator.flattened {
+		dotGenerator.generateFlattened(machine.Optimized)
+	} else {
+		dotGenerator.generateHierarchical(machine.Semantic)
+	}
+	return []Artifact{{Name: implementors.SnakeCase(name) + ".dot", Content: dotGenerator.output.String()}}, nil
+}
+
+func (dotGenerator *DotGenerator) generateHierarchical(ssm *semanticanalyzer.SemanticStateMachine) {
+	out := &dotGenerator.output
+	states := sortedSemanticStates(ssm)
+	var roots []*semanticanalyzer.SemanticState
+	roots, dotGenerator.children = stateTree(states)
+	dotGenerator.parents = parentsOf(dotGenerator.children)
+	dotGenerator.substates = substatesOf(states)
+	clusters := false
+	for _, state := range states {
+		clusters = clusters || dotGenerator.isCluster(state)
+	}
+
+	dotGenerator.writeHeader(ssm.FsmName, clusters)
+	for _, state := range roots {
+		dotGenerator.writeState(state, "  ")
+	}
+	fmt.Fprintf(out, "\n  __initial -> %s;\n", dotQuote(ssm.InitialState.Name))
+	for _, state := range states {
+		for _, transition := range state.Transitions {
+			attributes := []string{"label=" + dotQuote(edgeLabel(transition.Event, transition.Action))}
+			if dotGenerator.isCluster(state) && !dotGenerator.drawnInside(transition.NextState, state) {
+				attributes = append(attributes, "ltail="+dotQuote("cluster_"+state.Name))
+			}
+			fmt.Fprintf(out, "  %s -> %s [%s];\n",
+				dotQuote(state.Name), dotQuote(transition.NextState.Name), strings.Join(attributes, ", "))
+		}
+	}
+	fmt.Fprintf(out, "}\n")
+}
+
+// writeState draws a superstate as a cluster holding an anchor node for its
+// own transitions: the state itself when it is concrete, a point when it is
+// abstract. Graphviz ignores ltail for edges that stay inside the cluster,
+// so the point is only hidden when none of them does.
+func (dotGenerator *DotGenerator) writeState(state *semanticanalyzer.SemanticState, indent string) {
+	out := &dotGenerator.output
+	label := stateLabel(state.Name, state.EntryActions, state.ExitActions)
+	if !dotGenerator.isCluster(state) {
+		fmt.Fprintf(out, "%s%s [label=%s];\n", indent, dotQuote(state.Name), dotQuote(label))
+		return
+	}
+
+	children := dotGenerator.children[state]
+	clusterLabel := state.Name
+	if state.AbstractState {
+		clusterLabel = label
+	}
+	if elsewhere := dotGenerator.substatesDrawnElsewhere(state); len(elsewhere) > 0 {
+		clusterLabel += `\nsuperstate of ` + strings.Join(elsewhere, ", ")
+	}
+	fmt.Fprintf(out, "%ssubgraph %s {\n", indent, dotQuote("cluster_"+state.Name))
+	fmt.Fprintf(out, "%s  label=%s;\n", indent, dotQuote(clusterLabel))
+	if len(children) == 0 {
+		fmt.Fprintf(out, "%s  style=dashed;\n", indent)
+	}
+	if !state.AbstractState {
+		fmt.Fprintf(out, "%s  %s [label=%s];\n", indent, dotQuote(state.Name), dotQuote(label))
+	} else if dotGenerator.hasInnerTransition(state) {
+		fmt.Fprintf(out, "%s  %s [shape=point];\n", indent, dotQuote(state.Name))
+	} else {
+		fmt.Fprintf(out, "%s  %s [shape=point, style=invis];\n", indent, dotQuote(state.Name))
+	}
+	for _, child := range children {
+		dotGenerator.writeState(child, indent+"  ")
+	}
+	fmt.Fprintf(out, "%s}\n", indent)
+}
+
+func (dotGenerator *DotGenerator) isCluster(state *semanticanalyzer.SemanticState) bool {
+	return state.AbstractState || len(dotGenerator.substates[state]) > 0
+}
+
+// drawnInside tells whether state is drawn in the cluster of superState, or
+// is superState itself.
+func (dotGenerator *DotGenerator) drawnInside(state, superState *semanticanalyzer.SemanticState) bool {
+	for ; state != nil; state = dotGenerator.parents[state] {
+		if state == superState {
+			return true
+		}
+	}
+	return false
+}
+
+func (dotGenerator *DotGenerator) hasInnerTransition(state *semanticanalyzer.SemanticState) bool {
+	for _, transition := range state.Transitions {
+		if dotGenerator.drawnInside(transition.NextState, state) {
+			return true
+		}
+	}
+	return false
+}
+
+func (dotGenerator *DotGenerator) substatesDrawnElsewhere(state *semanticanalyzer.SemanticState) []string {
+	return substatesDrawnElsewhere(state, dotGenerator.substates, dotGenerator.parents)
+}
+
+func (dotGenerator *DotGenerator) generateFlattened(osm *optimizer.OptimizedStateMachine) {
+	out := &dotGenerator.output
+	dotGenerator.writeHeader(osm.Header.Fsm, false)
+	for _, state := range osm.States {
+		fmt.Fprintf(out, "  %s;\n", dotQuote(state))
+	}
+	fmt.Fprintf(out, "\n  __initial -> %s;\n", dotQuote(osm.Header.Initial))
+	for _, transition := range osm.Transitions {
+		for _, subTransition := range transition.SubTransitions {
+			fmt.Fprintf(out, "  %s -> %s [label=%s];\n", dotQuote(transition.CurrentState),
+				dotQuote(subTransition.NextState), dotQuote(edgeLabel(subTransition.Event, subTransition.Actions)))
+		}
+	}
+	fmt.Fprintf(out, "}\n")
+}
+
+func (dotGenerator *DotGenerator) writeHeader(name string, compound bool) {
+	out := &dotGenerator.output
+	fmt.Fprintf(out, "// Generated by smc. Do not edit.\n")
+	fmt.Fprintf(out, "digraph %s {\n", dotQuote(name))
+	graphAttributes := "rankdir=LR"
+	if compound {
+		graphAttributes += ", compound=true"
+	}
+	fmt.Fprintf(out, "  graph [%s];\n", graphAttributes)
+	fmt.Fprintf(out, "  node [shape=box, style=rounded];\n")
+	fmt.Fprintf(out, "  __initial [shape=point, width=0.15];\n\n")
+}
+
+// stateTree nests every state in the first of its superstates by name, as
+// diagrams cannot draw a state inside several superstates. It returns the
+// states that have no superstate and the children of every superstate.
+func stateTree(states []*semanticanalyzer.SemanticState) (
+	[]*semanticanalyzer.SemanticState,
+	map[*semanticanalyzer.SemanticState][]*semanticanalyzer.SemanticState,
+) {
+	roots := []*semanticanalyzer.SemanticState{}
+	children := map[*semanticanalyzer.SemanticState][]*semanticanalyzer.SemanticState{}
+	for _, state := range states {
+		if superStates := sortedSuperStatesOf(state); len(superStates) > 0 {
+			children[superStates[0]] = append(children[superStates[0]], state)
+		} else {
+			roots = append(roots, state)
+		}
+	}
+	return roots, children
+}
+
+// substatesOf lists the substates of every superstate, including the ones
+// stateTree draws in another superstate.
+func substatesOf(
+	states []*semanticanalyzer.SemanticState,
+) map[*semanticanalyzer.SemanticState][]*semanticanalyzer.SemanticState {
+	substates := map[*semanticanalyzer.SemanticState][]*semanticanalyzer.SemanticState{}
+	for _, state := range states {
+		for _, superState := range sortedSuperStatesOf(state) {
+			substates[superState] = append(substates[superState], state)
+		}
+	}
+	return substates
+}
+
+func parentsOf(
+	children map[*semanticanalyzer.SemanticState][]*semanticanalyzer.SemanticState,
+) map[*semanticanalyzer.SemanticState]*semanticanalyzer.SemanticState {
+	parents := map[*semanticanalyzer.SemanticState]*semanticanalyzer.SemanticState{}
+	for parent, substates := range children {
+		for _, child := range substates {
+			parents[child] = parent
+		}
+	}
+	return parents
+}
+
+// substatesDrawnElsewhere names the substates of state that stateTree put
+// in another superstate.
+func substatesDrawnElsewhere(
+	state *semanticanalyzer.SemanticState,
+	substates map[*semanticanalyzer.SemanticState][]*semanticanalyzer.SemanticState,
+	parents map[*semanticanalyzer.SemanticState]*semanticanalyzer.SemanticState,
+) []string {
+	elsewhere := []string{}
+	for _, substate := range substates[state] {
+		if parents[substate] != state {
+			elsewhere = append(elsewhere, substate.Name)
+		}
+	}
+	return elsewhere
+}
+
+// stateLabel lists entry and exit actions under the state name, one per
+// line, in the UML "entry / action" notation.
+func stateLabel(name string, entryActions, exitActions []string) string {
+	lines := []string{name}
+	for _, action := range entryActions {
+		lines = append(lines, "entry / "+action)
+	}
+	for _, action := range exitActions {
+		lines = append(lines, "exit / "+action)
+	}
+	return strings.Join(lines, `\n`)
+}
+
+func edgeLabel(event string, actions []string) string {
+	if len(actions) == 0 {
+		return event
+	}
+	return event + " / " + strings.Join(actions, ", ")
+}
+
+// dotQuote quotes a DOT identifier. Backslashes are kept, as labels use the
+// \n escape sequence.
+func dotQuote(id string) string {
+	return `"` + strings.ReplaceAll(id, `"`, `\"`) + `"`
+}
@@ -0,0 +1,123 @@
+package generator
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDotGenerator(t *testing.T) {
+	artifacts := generate(t, twoCoinTurnstile, "dot", map[string]string{})
+	if len(artifacts) != 1 || artifacts[0].Name != "two_coin_turnstile.dot" {
+		t.Fatalf("unexpected artifacts %v", artifacts)
+	}
+	content := artifacts[0].Content
+	for _, expected := range []string{
+		"digraph \"TwoCoinTurnstile\" {\n  graph [rankdir=LR, compound=true];\n",
+		"  subgraph \"cluster_Base\" {\n" +
+			"    label=\"Base\";\n" +
+			"    \"Base\" [shape=point];\n" +
+			"    \"Alarming\" [label=\"Alarming\\nentry / alarmOn\\nexit / alarmOff\"];\n",
+		"  __initial -> \"Locked\";\n",
+		"  \"Base\" -> \"Locked\" [label=\"Reset / lock\"];\n",
+		"  \"Locked\" -> \"FirstCoin\" [label=\"Coin\"];\n",
+		"  \"Unlocked\" -> \"Unlocked\" [label=\"Coin / thankyou\"];\n",
+	} {
+		if !strings.Contains(content, expected) {
+			t.Errorf("expected generated graph to contain %q:\n%s", expected, content)
+		}
+	}
+	if strings.Contains(content, "\"Alarming\" -> \"Locked\"") {
+		t.Errorf("expected Reset to be drawn once, from the Base cluster:\n%s", content)
+	}
+}
+
+func TestDotGeneratorNestsSuperStates(t *testing.T) {
+	content := generate(t, multipleSuperStates, "dot", map[string]string{})[0].Content
+	for _, expected := range []string{
+		"  subgraph \"cluster_b1\" {\n" +
+			"    label=\"b1\\nentry / e1\\nexit / x1\";\n" +
+			"    \"b1\" [shape=point];\n" +
+			"    subgraph \"cluster_b2\" {\n" +
+			"      label=\"b2\\nentry / e2\\nexit / x2\";\n" +
+			"      \"b2\" [shape=point];\n" +
+			"      \"i\" [label=\"i\\nentry / ei\\nexit / xi\"];\n" +
+			"    }\n" +
+			"    \"j\" [label=\"j\"];\n" +
+			"  }\n",
+		"  subgraph \"cluster_b3\" {\n" +
+			"    label=\"b3\\nentry / e3\\nexit / x3\\nsuperstate of i\";\n" +
+			"    style=dashed;\n" +
+			"    \"b3\" [shape=point, style=invis];\n" +
+			"  }\n",
+		"  \"b2\" -> \"i\" [label=\"s\"];\n",
+		"  \"b3\" -> \"j\" [label=\"u / c\", ltail=\"cluster_b3\"];\n",
+	} {
+		if !strings.Contains(content, expected) {
+			t.Errorf("expected generated graph to contain %q:\n%s", expected, content)
+		}
+	}
+}
+
+func TestDotGeneratorLeavesClusterBorderOnlyForOutgoingEdges(t *testing.T) {
+	source := "FSM: f\nInitial: a\n{\n  (s) e a {}\n  a : s f o {}\n  o f a {}\n}\n"
+	content := generate(t, source, "dot", map[string]string{})[0].Content
+	for _, expected := range []string{
+		"    \"s\" [shape=point];\n",
+		"  \"s\" -> \"a\" [label=\"e\"];\n",
+		"  \"o\" [label=\"o\"];\n",
+	} {
+		if !strings.Contains(content, expected) {
+			t.Errorf("expected generated graph to contain %q:\n%s", expected, content)
+		}
+	}
+	if strings.Contains(content, "ltail") {
+		t.Errorf("expected no ltail for edges inside their cluster:\n%s", content)
+	}
+}
+
+func TestDotGeneratorConcreteSuperState(t *testing.T) {
+	source := "FSM: f\nInitial: s\n{\n  s <on { e t {} }\n  t : s { f s a }\n}\n"
+	content := generate(t, source, "dot", map[string]string{})[0].Content
+	expected := "  subgraph \"cluster_s\" {\n" +
+		"    label=\"s\";\n" +
+		"    \"s\" [label=\"s\\nentry / on\"];\n" +
+		"    \"t\" [label=\"t\"];\n" +
+		"  }\n"
+	if !strings.Contains(content, expected) {
+		t.Errorf("expected generated graph to contain %q:\n%s", expected, content)
+	}
+}
+
+func TestDotGeneratorFlattened(t *testing.T) {
+	content := generate(t, twoCoinTurnstile, "dot", map[string]string{"mode": "flattened"})[0].Content
+	for _, expected := range []string{
+		"  graph [rankdir=LR];\n",
+		"  \"Alarming\";\n",
+		"  \"Alarming\" -> \"Locked\" [label=\"Reset / alarmOff, lock\"];\n",
+		"  \"Locked\" -> \"Alarming\" [label=\"Pass / alarmOn\"];\n",
+		"  \"Unlocked\" -> \"Locked\" [label=\"Reset / lock\"];\n",
+	} {
+		if !strings.Contains(content, expected) {
+			t.Errorf("expected generated graph to contain %q:\n%s", expected, content)
+		}
+	}
+	if strings.Contains(content, "cluster") || strings.Contains(content, "\"Base\"") {
+		t.Errorf("expected no superstates in a flattened graph:\n%s", content)
+	}
+}
+
+func TestDotUnknownMode(t *testing.T) {
+	if _, err := NewGenerator("dot", map[string]string{"mode": "nested"}); err == nil {
+		t.Fatal("expected an error for an unknown DOT mode")
+	}
+}
+
+func TestDotRendersWithGraphviz(t *testing.T) {
+	dot := lookPath(t, "dot")
+	for _, mode := range []string{"hierarchical", "flattened"} {
+		directory := t.TempDir()
+		writeArtifacts(t, directory, generate(t, twoCoinTurnstile, "dot", map[string]string{"mode": mode}))
+		run(t, directory, dot, "-Tsvg", "-o", filepath.Join(directory, "turnstile.svg"), "two_coin_turnstile.dot")
+	}
+}
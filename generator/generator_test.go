@@ -0,0 +1,129 @@
+package generator
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/larkvincer/dsl-fsm/lexer"
+	"github.com/larkvincer/dsl-fsm/optimizer"
+	"github.com/larkvincer/dsl-fsm/parser"
+	"github.com/larkvincer/dsl-fsm/semanticanalyzer"
+	"github.com/larkvincer/dsl-fsm/tokens"
+)
+
+const twoCoinTurnstile = "" +
+	"Actions: Turnstile\n" +
+	"FSM: TwoCoinTurnstile\n" +
+	"Initial: Locked\n" +
+	"{\n" +
+	"  (Base) Reset Locked lock\n" +
+	"  Locked : Base {\n" +
+	"    Pass Alarming {}\n" +
+	"    Coin FirstCoin {}\n" +
+	"  }\n" +
+	"  Alarming : Base <alarmOn >alarmOff {}\n" +
+	"  FirstCoin : Base {\n" +
+	"    Pass Alarming {}\n" +
+	"    Coin Unlocked unlock\n" +
+	"  }\n" +
+	"  Unlocked : Base {\n" +
+	"    Pass Locked lock\n" +
+	"    Coin * thankyou\n" +
+	"  }\n" +
+	"}\n"
+
+// twoCoinTurnstileTrace is what every generated program prints when it
+// feeds twoCoinTurnstileEvents to a TwoCoinTurnstile: each action on its own
+// line, unhandled events as "unhandled <state> <event>", and the final state.
+var twoCoinTurnstileEvents = []string{"Coin", "Coin", "Coin", "Pass", "Pass", "Pass", "Reset", "Reset"}
+
+const twoCoinTurnstileTrace = "" +
+	"unlock\n" +
+	"thankyou\n" +
+	"lock\n" +
+	"alarmOn\n" +
+	"unhandled Alarming Pass\n" +
+	"alarmOff\n" +
+	"lock\n" +
+	"lock\n" +
+	"Locked\n"
+
+func analyze(t *testing.T, source string) *semanticanalyzer.SemanticStateMachine {
+	t.Helper()
+	syntaxBuilder := parser.NewFsmSyntaxBuilder()
+	syntaxParser := parser.NewParser(syntaxBuilder)
+	lexer.New(syntaxParser).Lex(source)
+	syntaxParser.HandleEvent(tokens.EOF, -1, -1)
+	syntax := syntaxBuilder.GetFSM()
+	if len(syntax.Errors) > 0 {
+		t.Fatalf("syntax errors: %v", syntax.Errors)
+	}
+	semanticStateMachine := semanticanalyzer.New().Analyze(syntax)
+	if len(semanticStateMachine.Errors) > 0 {
+		t.Fatalf("semantic errors: %v", semanticStateMachine.Errors)
+	}
+	return semanticStateMachine
+}
+
+func generate(t *testing.T, source, language string, flags map[string]string) []Artifact {
+	t.Helper()
+	codeGenerator, err := NewGenerator(language, flags)
+	if err != nil {
+		t.Fatal(err)
+	}
+	semanticStateMachine := analyze(t, source)
+	artifacts, err := codeGenerator.Generate(&StateMachine{
+		Semantic:  semanticStateMachine,
+		Optimized: optimizer.Optimize(*semanticStateMachine),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return artifacts
+}
+
+// generateError generates code for source, which must be valid, and
+// returns the error of the generator.
+func generateError(t *testing.T, source, language string, flags map[string]string) error {
+	t.Helper()
+	codeGenerator, err := NewGenerator(language, flags)
+	if err != nil {
+		t.Fatal(err)
+	}
+	semanticStateMachine := analyze(t, source)
+	_, err = codeGenerator.Generate(&StateMachine{
+		Semantic:  semanticStateMachine,
+		Optimized: optimizer.Optimize(*semanticStateMachine),
+	})
+	return err
+}
+
+func writeArtifacts(t *testing.T, directory string, artifacts []Artifact) {
+	t.Helper()
+	if err := WriteArtifacts(NewDiskSink(directory), artifacts); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func lookPath(t *testing.T, tool string) string {
+	t.Helper()
+	path, err := exec.LookPath(tool)
+	if err != nil {
+		t.Skipf("%s is not installed", tool)
+	}
+	return path
+}
+
+func run(t *testing.T, directory, tool string, args ...string) string {
+	t.Helper()
+	command := exec.Command(tool, args...)
+	command.Dir = directory
+	command.Env = append(os.Environ(), "GOFLAGS=")
+	output, err := command.CombinedOutput()
+	if err != nil {
+		t.Fatalf("%s %v: %v\n%s", filepath.Base(tool), args, err, output)
+	}
+	return string(output)
+}
@@ -0,0 +1,195 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/larkvincer/dsl-fsm/generator/implementors"
+	"github.com/larkvincer/dsl-fsm/optimizer"
+)
+
+type BeamLanguage int
+
+const (
+	Erlang BeamLanguage = iota
+	Elixir
+)
+
+// GenStatemGenerator emits a gen_statem callback module in state_functions
+// mode: one function per state with one clause per (state, event). Events
+// are casts. Actions are calls into the module named by the Actions header;
+// each takes the machine data and returns the new data, which is threaded
+// through the actions of a transition. Unhandled events go to the
+// module's unhandled_transition(State, Event, Data). Names become snake_case
+// atoms, so names that are reserved words, that collide once converted or
+// whose functions would clash with start_link/1, state/1, init/1,
+// module_info/1 or the terminate/3 callback are rejected, as is an abstract
+// initial state.
+type GenStatemGenerator struct {
+	language BeamLanguage
+	flags    map[string]string
+	output   strings.Builder
+}
+
+func NewGenStatemGenerator(language BeamLanguage, flags map[string]string) *GenStatemGenerator {
+	return &GenStatemGenerator{
+		language: language,
+		flags:    flags,
+	}
+}
+
+// genStatemEventFunctions and genStatemStateFunctions are the functions
+// every generated module defines, the compiler adds or gen_statem calls,
+// with the arity of the function of an event and of a state.
+var (
+	genStatemEventFunctions = map[string]bool{"start_link": true, "state": true, "init": true, "module_info": true}
+	genStatemStateFunctions = map[string]bool{"terminate": true}
+)
+
+var beamReservedWords = map[BeamLanguage]map[string]bool{
+	Erlang: setOf("after", "and", "andalso", "band", "begin", "bnot", "bor", "bsl", "bsr", "bxor", "case", "catch",
+		"cond", "div", "else", "end", "fun", "if", "let", "maybe", "not", "of", "or", "orelse", "receive", "rem",
+		"try", "when", "xor"),
+	Elixir: setOf("after", "and", "catch", "do", "else", "end", "false", "fn", "in", "nil", "not", "or", "rescue",
+		"true", "when"),
+}
+
+func setOf(names ...string) map[string]bool {
+	set := make(map[string]bool, len(names))
+	for _, name := range names {
+		set[name] = true
+	}
+	return set
+}
+
+func (genStatem *GenStatemGenerator) Generate(machine *StateMachine) ([]Artifact, error) {
+	osm := machine.Optimized
+	if err := checkInitialState(osm); err != nil {
+		return nil, err
+	}
+	if err := genStatem.checkNames(osm); err != nil {
+		return nil, err
+	}
+	genStatem.output.Reset()
+	actionsModule := osm.Header.Actions
+	if actionsModule == "" {
+		actionsModule = osm.Header.Fsm + "Actions"
+	}
+
+	if genStatem.language == Elixir {
+		genStatem.generateElixir(osm, actionsModule)
+		return []Artifact{{
+			Name:    implementors.SnakeCase(osm.Header.Fsm) + ".ex",
+			Content: genStatem.output.String(),
+		}}, nil
+	}
+	genStatem.generateErlang(osm, implementors.SnakeCase(actionsModule))
+	return []Artifact{{
+		Name:    implementors.SnakeCase(osm.Header.Fsm) + ".erl",
+		Content: genStatem.output.String(),
+	}}, nil
+}
+
+func (genStatem *GenStatemGenerator) checkNames(osm *optimizer.OptimizedStateMachine) error {
+	for _, kind := range []struct {
+		name      string
+		names     []string
+		functions map[string]bool
+		arity     int
+	}{{"state", osm.States, genStatemStateFunctions, 3}, {"event", osm.Events, genStatemEventFunctions, 1}, {"action", osm.Actions, nil, 0}} {
+		atoms := map[string]string{}
+		for _, name := range kind.names {
+			atom := implementors.SnakeCase(name)
+			if beamReservedWords[genStatem.language][atom] {
+				return fmt.Errorf("%s %s of %s becomes the reserved word %s", kind.name, name, osm.Header.Fsm, atom)
+			}
+			if kind.functions[atom] {
+				return fmt.Errorf("%s %s of %s collides with the %s/%d function of the module",
+					kind.name, name, osm.Header.Fsm, atom, kind.arity)
+			}
+			if other, ok := atoms[atom]; ok {
+				return fmt.Errorf("%ss %s and %s of %s both become %s", kind.name, other, name, osm.Header.Fsm, atom)
+			}
+			atoms[atom] = name
+		}
+	}
+	return nil
+}
+
+func (genStatem *GenStatemGenerator) generateErlang(osm *optimizer.OptimizedStateMachine, actionsModule string) {
+	out := &genStatem.output
+	moduleName := implementors.SnakeCase(osm.Header.Fsm)
+	fmt.Fprintf(out, "%%%% Generated by smc. Do not edit.\n")
+	fmt.Fprintf(out, "-module(%s).\n", moduleName)
+	fmt.Fprintf(out, "-behaviour(gen_statem).\n\n")
+
+	api := []string{"start_link/1", "state/1"}
+	for _, event := range osm.Events {
+		api = append(api, implementors.SnakeCase(event)+"/1")
+	}
+	stateFunctions := []string{}
+	for _, state := range osm.States {
+		stateFunctions = append(stateFunctions, implementors.SnakeCase(state)+"/3")
+	}
+	fmt.Fprintf(out, "-export([%s]).\n", strings.Join(api, ", "))
+	fmt.Fprintf(out, "-export([init/1, callback_mode/0]).\n")
+	fmt.Fprintf(out, "-export([%s]).\n\n", strings.Join(stateFunctions, ", "))
+
+	fmt.Fprintf(out, "start_link(Data) ->\n    gen_statem:start_link(?MODULE, Data, []).\n\n")
+	fmt.Fprintf(out, "state(Pid) ->\n    gen_statem:call(Pid, state).\n\n")
+	for _, event := range osm.Events {
+		fmt.Fprintf(out, "%s(Pid) ->\n    gen_statem:cast(Pid, %s).\n\n", implementors.SnakeCase(event), implementors.SnakeCase(event))
+	}
+
+	fmt.Fprintf(out, "callback_mode() ->\n    state_functions.\n\n")
+	fmt.Fprintf(out, "init(Data) ->\n    {ok, %s, Data}.\n", implementors.SnakeCase(osm.Header.Initial))
+
+	for _, transition := range osm.Transitions {
+		state := implementors.SnakeCase(transition.CurrentState)
+		out.WriteString("\n")
+		for _, subTransition := range transition.SubTransitions {
+			fmt.Fprintf(out, "%s(cast, %s, Data0) ->\n", state, implementors.SnakeCase(subTransition.Event))
+			for i, action := range subTransition.Actions {
+				fmt.Fprintf(out, "    Data%d = %s:%s(Data%d),\n", i+1, actionsModule, implementors.SnakeCase(action), i)
+			}
+			fmt.Fprintf(out, "    {next_state, %s, Data%d};\n",
+				implementors.SnakeCase(subTransition.NextState), len(subTransition.Actions))
+		}
+		fmt.Fprintf(out, "%s({call, From}, state, _Data) ->\n", state)
+		fmt.Fprintf(out, "    {keep_state_and_data, [{reply, From, %s}]};\n", state)
+		fmt.Fprintf(out, "%s(cast, Event, Data) ->\n", state)
+		fmt.Fprintf(out, "    {keep_state, %s:unhandled_transition(%s, Event, Data)}.\n", actionsModule, state)
+	}
+}
+
+func (genStatem *GenStatemGenerator) generateElixir(osm *optimizer.OptimizedStateMachine, actionsModule string) {
+	out := &genStatem.output
+	fmt.Fprintf(out, "# Generated by smc. Do not edit.\n")
+	fmt.Fprintf(out, "defmodule %s do\n", osm.Header.Fsm)
+	fmt.Fprintf(out, "  @behaviour :gen_statem\n\n")
+
+	fmt.Fprintf(out, "  def start_link(data), do: :gen_statem.start_link(__MODULE__, data, [])\n")
+	fmt.Fprintf(out, "  def state(pid), do: :gen_statem.call(pid, :state)\n")
+	for _, event := range osm.Events {
+		fmt.Fprintf(out, "  def %s(pid), do: :gen_statem.cast(pid, :%s)\n", implementors.SnakeCase(event), implementors.SnakeCase(event))
+	}
+
+	fmt.Fprintf(out, "\n  @impl :gen_statem\n  def callback_mode, do: :state_functions\n")
+	fmt.Fprintf(out, "\n  @impl :gen_statem\n  def init(data), do: {:ok, :%s, data}\n", implementors.SnakeCase(osm.Header.Initial))
+
+	for _, transition := range osm.Transitions {
+		state := implementors.SnakeCase(transition.CurrentState)
+		for _, subTransition := range transition.SubTransitions {
+			fmt.Fprintf(out, "\n  def %s(:cast, :%s, data) do\n", state, implementors.SnakeCase(subTransition.Event))
+			for _, action := range subTransition.Actions {
+				fmt.Fprintf(out, "    data = %s.%s(data)\n", actionsModule, implementors.SnakeCase(action))
+			}
+			fmt.Fprintf(out, "    {:next_state, :%s, data}\n  end\n", implementors.SnakeCase(subTransition.NextState))
+		}
+		fmt.Fprintf(out, "\n  def %s({:call, from}, :state, _data) do\n", state)
+		fmt.Fprintf(out, "    {:keep_state_and_data, [{:reply, from, :%s}]}\n  end\n", state)
+		fmt.Fprintf(out, "\n  def %s(:cast, event, data) do\n", state)
+		fmt.Fprintf(out, "    {:keep_state, %s.unhandled_transition(:%s, event, data)}\n  end\n", actionsModule, state)
+	}
+	fmt.Fprintf(out, "end\n")
+}
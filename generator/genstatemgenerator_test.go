@@ -0,0 +1,100 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestErlangGenStatem(t *testing.T) {
+	artifacts := generate(t, twoCoinTurnstile, "erlang", map[string]string{})
+	if len(artifacts) != 1 || artifacts[0].Name != "two_coin_turnstile.erl" {
+		t.Fatalf("unexpected artifacts %v", artifacts)
+	}
+	content := artifacts[0].Content
+	for _, expected := range []string{
+		"-module(two_coin_turnstile).\n-behaviour(gen_statem).\n",
+		"-export([alarming/3, first_coin/3, locked/3, unlocked/3]).\n",
+		"callback_mode() ->\n    state_functions.\n",
+		"init(Data) ->\n    {ok, locked, Data}.\n",
+		"alarming(cast, reset, Data0) ->\n" +
+			"    Data1 = turnstile:alarm_off(Data0),\n" +
+			"    Data2 = turnstile:lock(Data1),\n" +
+			"    {next_state, locked, Data2};\n",
+		"locked(cast, coin, Data0) ->\n    {next_state, first_coin, Data0};\n",
+		"unlocked(cast, Event, Data) ->\n    {keep_state, turnstile:unhandled_transition(unlocked, Event, Data)}.\n",
+	} {
+		if !strings.Contains(content, expected) {
+			t.Errorf("expected generated code to contain %q:\n%s", expected, content)
+		}
+	}
+}
+
+func TestElixirGenStatem(t *testing.T) {
+	artifacts := generate(t, twoCoinTurnstile, "elixir", map[string]string{})
+	if len(artifacts) != 1 || artifacts[0].Name != "two_coin_turnstile.ex" {
+		t.Fatalf("unexpected artifacts %v", artifacts)
+	}
+	content := artifacts[0].Content
+	for _, expected := range []string{
+		"defmodule TwoCoinTurnstile do\n  @behaviour :gen_statem\n",
+		"  def coin(pid), do: :gen_statem.cast(pid, :coin)\n",
+		"  def callback_mode, do: :state_functions\n",
+		"  def alarming(:cast, :reset, data) do\n" +
+			"    data = Turnstile.alarm_off(data)\n" +
+			"    data = Turnstile.lock(data)\n" +
+			"    {:next_state, :locked, data}\n  end\n",
+	} {
+		if !strings.Contains(content, expected) {
+			t.Errorf("expected generated code to contain %q:\n%s", expected, content)
+		}
+	}
+}
+
+func TestGenStatemActionsModuleDefaultsToFsmName(t *testing.T) {
+	artifacts := generate(t, "FSM: Door\nInitial: closed\n{\nclosed open closed ring\n}\n", "erlang", map[string]string{})
+	if !strings.Contains(artifacts[0].Content, "door_actions:ring(Data0)") {
+		t.Fatalf("expected calls into door_actions:\n%s", artifacts[0].Content)
+	}
+}
+
+func TestGenStatemRejectsNamesTheModuleCannotDefine(t *testing.T) {
+	for name, test := range map[string]struct {
+		language, source, message string
+	}{
+		"abstract initial state": {"erlang",
+			"FSM: f\nInitial: b\n{\n  (b) e c {}\n  c : b f c {}\n}\n",
+			"initial state b of f is abstract"},
+		"event state": {"erlang", "FSM: f\nInitial: a\n{\n  a state a {}\n}\n",
+			"event state of f collides with the state/1 function of the module"},
+		"event StartLink": {"elixir", "FSM: f\nInitial: a\n{\n  a StartLink a {}\n}\n",
+			"event StartLink of f collides with the start_link/1 function of the module"},
+		"state terminate": {"erlang", "FSM: f\nInitial: terminate\n{\n  terminate e terminate {}\n}\n",
+			"state terminate of f collides with the terminate/3 function of the module"},
+		"erlang reserved state": {"erlang", "FSM: f\nInitial: receive\n{\n  receive e receive {}\n}\n",
+			"state receive of f becomes the reserved word receive"},
+		"erlang reserved action": {"erlang", "FSM: f\nInitial: a\n{\n  a e a begin\n}\n",
+			"action begin of f becomes the reserved word begin"},
+		"elixir reserved event": {"elixir", "FSM: f\nInitial: a\n{\n  a do a {}\n}\n",
+			"event do of f becomes the reserved word do"},
+		"colliding events": {"erlang", "FSM: f\nInitial: a\n{\n  a {\n    LockDoor a {}\n    lockDoor a {}\n  }\n}\n",
+			"events LockDoor and lockDoor of f both become lock_door"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := generateError(t, test.source, test.language, map[string]string{})
+			if err == nil || err.Error() != test.message {
+				t.Fatalf("expected %q, got %v", test.message, err)
+			}
+		})
+	}
+	// Elixir can use Erlang's reserved words as names.
+	if err := generateError(t, "FSM: f\nInitial: receive\n{\n  receive e receive {}\n}\n", "elixir", map[string]string{}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestErlangGenStatemCompiles(t *testing.T) {
+	erlc := lookPath(t, "erlc")
+	directory := t.TempDir()
+	writeArtifacts(t, directory, generate(t, twoCoinTurnstile, "erlang", map[string]string{}))
+	run(t, directory, erlc, "two_coin_turnstile.erl")
+}
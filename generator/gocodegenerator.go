@@ -0,0 +1,60 @@
+package generator
+
+import (
+	"go/format"
+	"strings"
+
+	"github.com/larkvincer/dsl-fsm/generator/implementors"
+	nscgenerator "github.com/larkvincer/dsl-fsm/generator/nestedswitchcasegenerator"
+	ttgenerator "github.com/larkvincer/dsl-fsm/generator/transitiontablegenerator"
+)
+
+type GoCodeGenerator struct {
+	goNestedSwitchCaseImplementor *implementors.GoNestedSwitchCaseImplementor
+}
+
+func NewGoCodeGenerator(
+	goNestedSwitchCaseImplementor *implementors.GoNestedSwitchCaseImplementor,
+) *GoCodeGenerator {
+	return &GoCodeGenerator{
+		goNestedSwitchCaseImplementor: goNestedSwitchCaseImplementor,
+	}
+}
+
+func (goGenerator *GoCodeGenerator) GetImplementer() nscgenerator.NSCNodeVisitor {
+	return goGenerator.goNestedSwitchCaseImplementor
+}
+
+// GetArtifacts returns the generated file run through gofmt. Output that
+// gofmt rejects, e.g. because a DSL name is a Go keyword, is kept as is so
+// the Go compiler can point at the problem.
+func (goGenerator *GoCodeGenerator) GetArtifacts(fsmName string) []Artifact {
+	content := goGenerator.goNestedSwitchCaseImplementor.Output
+	if formatted, err := format.Source([]byte(content)); err == nil {
+		content = string(formatted)
+	}
+	return []Artifact{{
+		Name:    strings.ToLower(fsmName) + ".go",
+		Content: content,
+	}}
+}
+
+// GoTableCodeGenerator shares GoCodeGenerator's artifacts, as the table
+// implementor writes into its embedded nested switch/case implementor.
+type GoTableCodeGenerator struct {
+	GoCodeGenerator
+	goTransitionTableImplementor *implementors.GoTransitionTableImplementor
+}
+
+func NewGoTableCodeGenerator(
+	goTransitionTableImplementor *implementors.GoTransitionTableImplementor,
+) *GoTableCodeGenerator {
+	return &GoTableCodeGenerator{
+		GoCodeGenerator:              *NewGoCodeGenerator(&goTransitionTableImplementor.GoNestedSwitchCaseImplementor),
+		goTransitionTableImplementor: goTransitionTableImplementor,
+	}
+}
+
+func (goGenerator *GoTableCodeGenerator) GetTableImplementer() ttgenerator.TransitionTableImplementor {
+	return goGenerator.goTransitionTableImplementor
+}
@@ -0,0 +1,86 @@
+package generator
+
+import (
+	"go/format"
+	"strings"
+	"testing"
+)
+
+const goTurnstileMain = `package main
+
+import (
+	"fmt"
+
+	fsm "example.com/generated/turnstile"
+)
+
+type actions struct{}
+
+func (actions) AlarmOff() { fmt.Println("alarmOff") }
+func (actions) AlarmOn()  { fmt.Println("alarmOn") }
+func (actions) Lock()     { fmt.Println("lock") }
+func (actions) Thankyou() { fmt.Println("thankyou") }
+func (actions) Unlock()   { fmt.Println("unlock") }
+
+func main() {
+	turnstile := fsm.NewTwoCoinTurnstile(actions{})
+	turnstile.UnhandledTransition = func(state fsm.State, event fsm.Event) {
+		fmt.Println("unhandled", state, event)
+	}
+	events := map[string]func(){"Coin": turnstile.Coin, "Pass": turnstile.Pass, "Reset": turnstile.Reset}
+	for _, event := range []string{%s} {
+		events[event]()
+	}
+	fmt.Println(turnstile.CurrentState())
+}
+`
+
+func TestGoArtifactIsGofmtClean(t *testing.T) {
+	artifacts := generate(t, twoCoinTurnstile, "go", map[string]string{"package": "turnstile"})
+	if len(artifacts) != 1 || artifacts[0].Name != "twocointurnstile.go" {
+		t.Fatalf("unexpected artifacts %v", artifacts)
+	}
+	formatted, err := format.Source([]byte(artifacts[0].Content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(formatted) != artifacts[0].Content {
+		t.Fatalf("generated code is not gofmt-clean:\n%s", artifacts[0].Content)
+	}
+	for _, expected := range []string{
+		"package turnstile\n",
+		"\tStateAlarming State = iota\n",
+		"type Turnstile interface {\n\tAlarmOff()\n",
+		"func (fsm *TwoCoinTurnstile) Coin() { fsm.handleEvent(EventCoin) }\n",
+		"func (fsm *TwoCoinTurnstile) handleEvent(event Event) {\n\tswitch fsm.state {\n",
+	} {
+		if !strings.Contains(artifacts[0].Content, expected) {
+			t.Errorf("expected generated code to contain %q:\n%s", expected, artifacts[0].Content)
+		}
+	}
+}
+
+func TestGoPackageDefaultsToFsmName(t *testing.T) {
+	artifacts := generate(t, twoCoinTurnstile, "go", map[string]string{})
+	if !strings.Contains(artifacts[0].Content, "package twocointurnstile\n") {
+		t.Fatalf("expected default package name:\n%s", artifacts[0].Content)
+	}
+}
+
+func TestGoArtifactRuns(t *testing.T) {
+	goTool := lookPath(t, "go")
+	directory := t.TempDir()
+	artifacts := generate(t, twoCoinTurnstile, "go", map[string]string{"package": "turnstile"})
+	artifacts[0].Name = "turnstile/" + artifacts[0].Name
+	events := `"` + strings.Join(twoCoinTurnstileEvents, `", "`) + `"`
+	artifacts = append(artifacts,
+		Artifact{Name: "go.mod", Content: "module example.com/generated\n\ngo 1.16\n"},
+		Artifact{Name: "main.go", Content: strings.Replace(goTurnstileMain, "%s", events, 1)},
+	)
+	writeArtifacts(t, directory, artifacts)
+
+	output := run(t, directory, goTool, "run", ".")
+	if output != twoCoinTurnstileTrace {
+		t.Fatalf("expected\n%s\nbut got\n%s", twoCoinTurnstileTrace, output)
+	}
+}
@@ -0,0 +1,285 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/larkvincer/dsl-fsm/generator/implementors"
+	"github.com/larkvincer/dsl-fsm/optimizer"
+)
+
+type HDL int
+
+const (
+	Verilog HDL = iota
+	VHDL
+)
+
+type StateEncoding string
+
+const (
+	BinaryEncoding StateEncoding = "binary"
+	OneHotEncoding StateEncoding = "onehot"
+	GrayEncoding   StateEncoding = "gray"
+)
+
+// HDLGenerator emits a synthesizable, clocked state machine. Every event is
+// an input strobe sampled on the rising clock edge; when several strobes are
+// high at once the first event in alphabetical order wins. Every action is
+// an output that pulses for one cycle after the transition that runs it, so
+// an action listed twice in one transition still gives a single pulse.
+// Strobes the current state does not handle pulse the unhandled output. A
+// synchronous rst returns to the initial state, which must be concrete to
+// have an encoding. The encoding flag selects the state register encoding.
+type HDLGenerator struct {
+	language HDL
+	encoding StateEncoding
+	flags    map[string]string
+	output   strings.Builder
+}
+
+func NewHDLGenerator(language HDL, flags map[string]string) (*HDLGenerator, error) {
+	hdlGenerator := &HDLGenerator{
+		language: language,
+		encoding: BinaryEncoding,
+		flags:    flags,
+	}
+	if encoding, ok := flags["encoding"]; ok {
+		hdlGenerator.encoding = StateEncoding(strings.ToLower(encoding))
+	}
+	switch hdlGenerator.encoding {
+	case BinaryEncoding, OneHotEncoding, GrayEncoding:
+		return hdlGenerator, nil
+	}
+	return nil, fmt.Errorf("unknown state encoding %q, expected binary, onehot or gray", flags["encoding"])
+}
+
+func (hdlGenerator *HDLGenerator) Generate(machine *StateMachine) ([]Artifact, error) {
+	osm := machine.Optimized
+	if err := checkInitialState(osm); err != nil {
+		return nil, err
+	}
+	hdlGenerator.output.Reset()
+	moduleName := implementors.SnakeCase(osm.Header.Fsm)
+	if hdlGenerator.language == VHDL {
+		hdlGenerator.generateVHDL(osm, moduleName)
+		return []Artifact{{Name: moduleName + ".vhd", Content: hdlGenerator.output.String()}}, nil
+	}
+	hdlGenerator.generateVerilog(osm, moduleName)
+	return []Artifact{{Name: moduleName + ".v", Content: hdlGenerator.output.String()}}, nil
+}
+
+func (hdlGenerator *HDLGenerator) generateVerilog(osm *optimizer.OptimizedStateMachine, moduleName string) {
+	out := &hdlGenerator.output
+	width := hdlGenerator.stateWidth(len(osm.States))
+
+	fmt.Fprintf(out, "// Generated by smc. Do not edit.\n")
+	fmt.Fprintf(out, "// State encoding: %s.\n", hdlGenerator.encoding)
+	fmt.Fprintf(out, "module %s (\n", moduleName)
+	fmt.Fprintf(out, "    input wire clk,\n")
+	fmt.Fprintf(out, "    input wire rst,\n")
+	for _, event := range osm.Events {
+		fmt.Fprintf(out, "    input wire %s,\n", eventPort(event))
+	}
+	for _, action := range osm.Actions {
+		fmt.Fprintf(out, "    output reg %s,\n", actionPort(action))
+	}
+	fmt.Fprintf(out, "    output reg unhandled,\n")
+	fmt.Fprintf(out, "    output reg [%d:0] state\n", width-1)
+	fmt.Fprintf(out, ");\n\n")
+
+	for i, state := range osm.States {
+		fmt.Fprintf(out, "    localparam [%d:0] %s = %d'b%s;\n", width-1, stateConstant(state), width, hdlGenerator.stateCode(i, width))
+	}
+	fmt.Fprintf(out, "\n    always @(posedge clk) begin\n")
+	for _, action := range osm.Actions {
+		fmt.Fprintf(out, "        %s <= 1'b0;\n", actionPort(action))
+	}
+	fmt.Fprintf(out, "        unhandled <= 1'b0;\n")
+	fmt.Fprintf(out, "        if (rst) begin\n")
+	fmt.Fprintf(out, "            state <= %s;\n", stateConstant(osm.Header.Initial))
+	fmt.Fprintf(out, "        end else begin\n")
+	fmt.Fprintf(out, "            case (state)\n")
+	for _, transition := range osm.Transitions {
+		fmt.Fprintf(out, "                %s: begin\n", stateConstant(transition.CurrentState))
+		keyword := "if"
+		for _, subTransition := range inEventOrder(osm.Events, transition) {
+			fmt.Fprintf(out, "                    %s (%s) begin\n", keyword, eventPort(subTransition.Event))
+			fmt.Fprintf(out, "                        state <= %s;\n", stateConstant(subTransition.NextState))
+			for _, action := range distinct(subTransition.Actions) {
+				fmt.Fprintf(out, "                        %s <= 1'b1;\n", actionPort(action))
+			}
+			fmt.Fprintf(out, "                    end\n")
+			keyword = "else if"
+		}
+		if unhandledEvents := unhandledEvents(osm.Events, transition); len(unhandledEvents) > 0 {
+			ports := []string{}
+			for _, event := range unhandledEvents {
+				ports = append(ports, eventPort(event))
+			}
+			fmt.Fprintf(out, "                    %s (%s) begin\n", keyword, strings.Join(ports, " || "))
+			fmt.Fprintf(out, "                        unhandled <= 1'b1;\n")
+			fmt.Fprintf(out, "                    end\n")
+		}
+		fmt.Fprintf(out, "                end\n")
+	}
+	fmt.Fprintf(out, "                default: state <= %s;\n", stateConstant(osm.Header.Initial))
+	fmt.Fprintf(out, "            endcase\n")
+	fmt.Fprintf(out, "        end\n")
+	fmt.Fprintf(out, "    end\n")
+	fmt.Fprintf(out, "endmodule\n")
+}
+
+func (hdlGenerator *HDLGenerator) generateVHDL(osm *optimizer.OptimizedStateMachine, entityName string) {
+	out := &hdlGenerator.output
+	width := hdlGenerator.stateWidth(len(osm.States))
+
+	fmt.Fprintf(out, "-- Generated by smc. Do not edit.\n")
+	fmt.Fprintf(out, "-- State encoding: %s.\n", hdlGenerator.encoding)
+	fmt.Fprintf(out, "library ieee;\n")
+	fmt.Fprintf(out, "use ieee.std_logic_1164.all;\n\n")
+	fmt.Fprintf(out, "entity %s is\n", entityName)
+	fmt.Fprintf(out, "    port (\n")
+	fmt.Fprintf(out, "        clk : in std_logic;\n")
+	fmt.Fprintf(out, "        rst : in std_logic;\n")
+	for _, event := range osm.Events {
+		fmt.Fprintf(out, "        %s : in std_logic;\n", eventPort(event))
+	}
+	for _, action := range osm.Actions {
+		fmt.Fprintf(out, "        %s : out std_logic;\n", actionPort(action))
+	}
+	fmt.Fprintf(out, "        unhandled : out std_logic;\n")
+	fmt.Fprintf(out, "        state : out std_logic_vector(%d downto 0)\n", width-1)
+	fmt.Fprintf(out, "    );\n")
+	fmt.Fprintf(out, "end entity;\n\n")
+
+	fmt.Fprintf(out, "architecture rtl of %s is\n", entityName)
+	for i, state := range osm.States {
+		fmt.Fprintf(out, "    constant %s : std_logic_vector(%d downto 0) := \"%s\";\n",
+			stateConstant(state), width-1, hdlGenerator.stateCode(i, width))
+	}
+	fmt.Fprintf(out, "    signal current_state : std_logic_vector(%d downto 0) := %s;\n", width-1, stateConstant(osm.Header.Initial))
+	fmt.Fprintf(out, "begin\n")
+	fmt.Fprintf(out, "    state <= current_state;\n\n")
+	fmt.Fprintf(out, "    process (clk)\n")
+	fmt.Fprintf(out, "    begin\n")
+	fmt.Fprintf(out, "        if rising_edge(clk) then\n")
+	for _, action := range osm.Actions {
+		fmt.Fprintf(out, "            %s <= '0';\n", actionPort(action))
+	}
+	fmt.Fprintf(out, "            unhandled <= '0';\n")
+	fmt.Fprintf(out, "            if rst = '1' then\n")
+	fmt.Fprintf(out, "                current_state <= %s;\n", stateConstant(osm.Header.Initial))
+	fmt.Fprintf(out, "            else\n")
+	fmt.Fprintf(out, "                case current_state is\n")
+	for _, transition := range osm.Transitions {
+		fmt.Fprintf(out, "                    when %s =>\n", stateConstant(transition.CurrentState))
+		keyword := "if"
+		for _, subTransition := range inEventOrder(osm.Events, transition) {
+			fmt.Fprintf(out, "                        %s %s = '1' then\n", keyword, eventPort(subTransition.Event))
+			fmt.Fprintf(out, "                            current_state <= %s;\n", stateConstant(subTransition.NextState))
+			for _, action := range distinct(subTransition.Actions) {
+				fmt.Fprintf(out, "                            %s <= '1';\n", actionPort(action))
+			}
+			keyword = "elsif"
+		}
+		if unhandledEvents := unhandledEvents(osm.Events, transition); len(unhandledEvents) > 0 {
+			conditions := []string{}
+			for _, event := range unhandledEvents {
+				conditions = append(conditions, eventPort(event)+" = '1'")
+			}
+			fmt.Fprintf(out, "                        %s %s then\n", keyword, strings.Join(conditions, " or "))
+			fmt.Fprintf(out, "                            unhandled <= '1';\n")
+			keyword = "elsif"
+		}
+		if keyword != "if" {
+			fmt.Fprintf(out, "                        end if;\n")
+		} else {
+			fmt.Fprintf(out, "                        null;\n")
+		}
+	}
+	fmt.Fprintf(out, "                    when others =>\n")
+	fmt.Fprintf(out, "                        current_state <= %s;\n", stateConstant(osm.Header.Initial))
+	fmt.Fprintf(out, "                end case;\n")
+	fmt.Fprintf(out, "            end if;\n")
+	fmt.Fprintf(out, "        end if;\n")
+	fmt.Fprintf(out, "    end process;\n")
+	fmt.Fprintf(out, "end architecture;\n")
+}
+
+func (hdlGenerator *HDLGenerator) stateWidth(states int) int {
+	if hdlGenerator.encoding == OneHotEncoding {
+		if states == 0 {
+			return 1
+		}
+		return states
+	}
+	width := 1
+	for 1<<width < states {
+		width++
+	}
+	return width
+}
+
+func (hdlGenerator *HDLGenerator) stateCode(index, width int) string {
+	code := index
+	switch hdlGenerator.encoding {
+	case OneHotEncoding:
+		code = 1 << index
+	case GrayEncoding:
+		code = index ^ (index >> 1)
+	}
+	return fmt.Sprintf("%0*b", width, code)
+}
+
+// inEventOrder orders the sub transitions by event name, which is the
+// priority of their strobes.
+func inEventOrder(events []string, transition optimizer.Transition) []optimizer.SubTransition {
+	ordered := []optimizer.SubTransition{}
+	for _, event := range events {
+		for _, subTransition := range transition.SubTransitions {
+			if subTransition.Event == event {
+				ordered = append(ordered, subTransition)
+			}
+		}
+	}
+	return ordered
+}
+
+func unhandledEvents(events []string, transition optimizer.Transition) []string {
+	handled := make(map[string]bool)
+	for _, subTransition := range transition.SubTransitions {
+		handled[subTransition.Event] = true
+	}
+	unhandled := []string{}
+	for _, event := range events {
+		if !handled[event] {
+			unhandled = append(unhandled, event)
+		}
+	}
+	return unhandled
+}
+
+func distinct(names []string) []string {
+	seen := make(map[string]bool)
+	result := []string{}
+	for _, name := range names {
+		if !seen[name] {
+			seen[name] = true
+			result = append(result, name)
+		}
+	}
+	return result
+}
+
+func stateConstant(state string) string {
+	return "S_" + strings.ToUpper(implementors.SnakeCase(state))
+}
+
+func eventPort(event string) string {
+	return "ev_" + implementors.SnakeCase(event)
+}
+
+func actionPort(action string) string {
+	return "act_" + implementors.SnakeCase(action)
+}
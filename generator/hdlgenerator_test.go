@@ -0,0 +1,110 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestVerilogGenerator(t *testing.T) {
+	artifacts := generate(t, twoCoinTurnstile, "verilog", map[string]string{})
+	if len(artifacts) != 1 || artifacts[0].Name != "two_coin_turnstile.v" {
+		t.Fatalf("unexpected artifacts %v", artifacts)
+	}
+	content := artifacts[0].Content
+	for _, expected := range []string{
+		"module two_coin_turnstile (\n",
+		"    input wire ev_coin,\n",
+		"    output reg act_alarm_on,\n",
+		"    output reg unhandled,\n    output reg [1:0] state\n);\n",
+		"    localparam [1:0] S_ALARMING = 2'b00;\n",
+		"    localparam [1:0] S_UNLOCKED = 2'b11;\n",
+		"        if (rst) begin\n            state <= S_LOCKED;\n",
+		"                S_ALARMING: begin\n" +
+			"                    if (ev_reset) begin\n" +
+			"                        state <= S_LOCKED;\n" +
+			"                        act_alarm_off <= 1'b1;\n" +
+			"                        act_lock <= 1'b1;\n" +
+			"                    end\n" +
+			"                    else if (ev_coin || ev_pass) begin\n" +
+			"                        unhandled <= 1'b1;\n",
+		"                default: state <= S_LOCKED;\n",
+	} {
+		if !strings.Contains(content, expected) {
+			t.Errorf("expected generated code to contain %q:\n%s", expected, content)
+		}
+	}
+}
+
+func TestVHDLGenerator(t *testing.T) {
+	artifacts := generate(t, twoCoinTurnstile, "vhdl", map[string]string{})
+	if len(artifacts) != 1 || artifacts[0].Name != "two_coin_turnstile.vhd" {
+		t.Fatalf("unexpected artifacts %v", artifacts)
+	}
+	content := artifacts[0].Content
+	for _, expected := range []string{
+		"entity two_coin_turnstile is\n",
+		"        ev_pass : in std_logic;\n",
+		"        act_thankyou : out std_logic;\n",
+		"        state : out std_logic_vector(1 downto 0)\n",
+		"    constant S_FIRST_COIN : std_logic_vector(1 downto 0) := \"01\";\n",
+		"    signal current_state : std_logic_vector(1 downto 0) := S_LOCKED;\n",
+		"                    when S_UNLOCKED =>\n" +
+			"                        if ev_coin = '1' then\n" +
+			"                            current_state <= S_UNLOCKED;\n" +
+			"                            act_thankyou <= '1';\n" +
+			"                        elsif ev_pass = '1' then\n",
+		"                    when others =>\n",
+	} {
+		if !strings.Contains(content, expected) {
+			t.Errorf("expected generated code to contain %q:\n%s", expected, content)
+		}
+	}
+}
+
+func TestHDLStateEncodings(t *testing.T) {
+	for _, test := range []struct {
+		encoding string
+		expected []string
+	}{
+		{"binary", []string{"[1:0] S_ALARMING = 2'b00", "[1:0] S_FIRST_COIN = 2'b01", "[1:0] S_LOCKED = 2'b10", "[1:0] S_UNLOCKED = 2'b11"}},
+		{"gray", []string{"[1:0] S_ALARMING = 2'b00", "[1:0] S_FIRST_COIN = 2'b01", "[1:0] S_LOCKED = 2'b11", "[1:0] S_UNLOCKED = 2'b10"}},
+		{"onehot", []string{"[3:0] S_ALARMING = 4'b0001", "[3:0] S_FIRST_COIN = 4'b0010", "[3:0] S_LOCKED = 4'b0100", "[3:0] S_UNLOCKED = 4'b1000"}},
+	} {
+		content := generate(t, twoCoinTurnstile, "verilog", map[string]string{"encoding": test.encoding})[0].Content
+		for _, expected := range test.expected {
+			if !strings.Contains(content, expected) {
+				t.Errorf("%s: expected generated code to contain %q:\n%s", test.encoding, expected, content)
+			}
+		}
+	}
+}
+
+func TestHDLUnknownEncoding(t *testing.T) {
+	if _, err := NewGenerator("vhdl", map[string]string{"encoding": "johnson"}); err == nil {
+		t.Fatal("expected an error for an unknown state encoding")
+	}
+}
+
+func TestHDLRejectsAbstractInitialState(t *testing.T) {
+	source := "FSM: f\nInitial: b\n{\n  (b) e c {}\n  c : b f c {}\n}\n"
+	for _, language := range []string{"verilog", "vhdl"} {
+		if err := generateError(t, source, language, map[string]string{}); err == nil ||
+			err.Error() != "initial state b of f is abstract" {
+			t.Errorf("%s: expected an error for the abstract initial state, got %v", language, err)
+		}
+	}
+}
+
+func TestVerilogSimulates(t *testing.T) {
+	iverilog := lookPath(t, "iverilog")
+	directory := t.TempDir()
+	writeArtifacts(t, directory, generate(t, twoCoinTurnstile, "verilog", map[string]string{"encoding": "onehot"}))
+	run(t, directory, iverilog, "-o", "two_coin_turnstile.vvp", "two_coin_turnstile.v")
+}
+
+func TestVHDLAnalyzes(t *testing.T) {
+	ghdl := lookPath(t, "ghdl")
+	directory := t.TempDir()
+	writeArtifacts(t, directory, generate(t, twoCoinTurnstile, "vhdl", map[string]string{"encoding": "gray"}))
+	run(t, directory, ghdl, "-a", "two_coin_turnstile.vhd")
+}
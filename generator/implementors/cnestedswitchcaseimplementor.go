@@ -0,0 +1,199 @@
+package implementors
+
+import (
+	"fmt"
+	"strings"
+
+	nscgenerator "github.com/larkvincer/dsl-fsm/generator/nestedswitchcasegenerator"
+)
+
+// CNestedSwitchCaseImplementor writes a header into Header and the matching
+// implementation into Output. Every identifier is prefixed with the FSM name
+// so several machines can be linked into one image. The machine is a plain
+// struct the caller allocates; actions are called through a const table of
+// function pointers that all receive the caller's context pointer.
+type CNestedSwitchCaseImplementor struct {
+	Output      string
+	Header      string
+	flags       map[string]string
+	className   string
+	actionsName string
+}
+
+func NewCNestedSwitchCaseImplementor(flags map[string]string) *CNestedSwitchCaseImplementor {
+	return &CNestedSwitchCaseImplementor{
+		flags: flags,
+	}
+}
+
+func (cImplementor *CNestedSwitchCaseImplementor) VisitSwitchCaseNode(
+	switchCaseNode *nscgenerator.SwitchCaseNode,
+) {
+	variableName := switchCaseNode.VariableName
+	if variableName == "state" {
+		variableName = "fsm->state"
+	}
+	cImplementor.Output += fmt.Sprintf("switch (%s) {\n", variableName)
+	switchCaseNode.GenerateCases(cImplementor)
+	cImplementor.Output += "}\n"
+}
+
+func (cImplementor *CNestedSwitchCaseImplementor) VisitCaseNode(caseNode *nscgenerator.CaseNode) {
+	cImplementor.Output += fmt.Sprintf("case %s:\n", cImplementor.enumerator(caseNode.SwitchName, caseNode.CaseName))
+	caseNode.CaseActionNode.Accept(cImplementor)
+	cImplementor.Output += "break;\n"
+}
+
+func (cImplementor *CNestedSwitchCaseImplementor) VisitFunctionalCallNode(
+	functionCallNode *nscgenerator.FunctionCallNode,
+) {
+	if functionCallNode.Argument != nil {
+		cImplementor.Output += fmt.Sprintf("%s(fsm, ", functionCallNode.FunctionName)
+		functionCallNode.Argument.Accept(cImplementor)
+		cImplementor.Output += ");\n"
+	} else {
+		cImplementor.Output += fmt.Sprintf("fsm->actions->%s(fsm->context);\n", functionCallNode.FunctionName)
+	}
+}
+
+func (cImplementor *CNestedSwitchCaseImplementor) VisitEnumNode(enumNode *nscgenerator.EnumNode) {
+	enumName := cImplementor.enumName(enumNode.Name)
+	cImplementor.Header += fmt.Sprintf("enum %s {\n", enumName)
+	for i, enumerator := range enumNode.Enumerators {
+		separator := ","
+		if i == len(enumNode.Enumerators)-1 {
+			separator = ""
+		}
+		cImplementor.Header += fmt.Sprintf("%s%s\n", cImplementor.enumerator(enumNode.Name, enumerator), separator)
+	}
+	cImplementor.Header += "};\n\n"
+
+	nameFunction := fmt.Sprintf(
+		"const char *%s_%s_name(enum %s %s)",
+		cImplementor.className, strings.ToLower(enumNode.Name), enumName, strings.ToLower(enumNode.Name),
+	)
+	cImplementor.Header += nameFunction + ";\n\n"
+	cImplementor.Output += nameFunction + " {\n"
+	cImplementor.Output += fmt.Sprintf("switch (%s) {\n", strings.ToLower(enumNode.Name))
+	for _, enumerator := range enumNode.Enumerators {
+		cImplementor.Output += fmt.Sprintf("case %s: return \"%s\";\n", cImplementor.enumerator(enumNode.Name, enumerator), enumerator)
+	}
+	cImplementor.Output += "}\n"
+	cImplementor.Output += "return \"?\";\n"
+	cImplementor.Output += "}\n\n"
+}
+
+func (cImplementor *CNestedSwitchCaseImplementor) VisitStatePropertyNode(
+	statePropertyNode *nscgenerator.StatePropertyNode,
+) {
+	initFunction := fmt.Sprintf(
+		"void %s_init(struct %s *fsm, const struct %s *actions, void *context)",
+		cImplementor.className, cImplementor.className, cImplementor.actionsName,
+	)
+	cImplementor.Header += initFunction + ";\n"
+	cImplementor.Output += initFunction + " {\n"
+	cImplementor.Output += fmt.Sprintf("fsm->state = %s;\n", cImplementor.enumerator("State", statePropertyNode.InitialState))
+	cImplementor.Output += "fsm->actions = actions;\n"
+	cImplementor.Output += "fsm->context = context;\n"
+	cImplementor.Output += "}\n\n"
+
+	cImplementor.Output += fmt.Sprintf(
+		"static void setState(struct %s *fsm, enum %s state) {\n",
+		cImplementor.className, cImplementor.enumName("State"),
+	)
+	cImplementor.Output += "fsm->state = state;\n"
+	cImplementor.Output += "}\n\n"
+}
+
+func (cImplementor *CNestedSwitchCaseImplementor) VisitEventDelegatorsNode(
+	eventDelegatorsNode *nscgenerator.EventDelegatorsNode,
+) {
+	for _, event := range eventDelegatorsNode.Events {
+		delegator := fmt.Sprintf("void %s_%s(struct %s *fsm)", cImplementor.className, event, cImplementor.className)
+		cImplementor.Header += delegator + ";\n"
+		cImplementor.Output += fmt.Sprintf(
+			"%s {\nhandle_event(fsm, %s);\n}\n\n",
+			delegator, cImplementor.enumerator("Event", event),
+		)
+	}
+}
+
+func (cImplementor *CNestedSwitchCaseImplementor) VisitFSMClassNode(fsmClassNode *nscgenerator.FSMClassNode) {
+	cImplementor.className = fsmClassNode.ClassName
+	cImplementor.actionsName = fsmClassNode.ActionsName
+	if cImplementor.actionsName == "" {
+		cImplementor.actionsName = fsmClassNode.ClassName + "Actions"
+	}
+	guard := strings.ToUpper(fsmClassNode.ClassName) + "_H"
+
+	cImplementor.Header += "/* Generated by smc. Do not edit. */\n"
+	cImplementor.Header += fmt.Sprintf("#ifndef %s\n#define %s\n\n", guard, guard)
+	cImplementor.Output += "/* Generated by smc. Do not edit. */\n"
+	cImplementor.Output += "#include <stddef.h>\n"
+	cImplementor.Output += fmt.Sprintf("#include \"%s.h\"\n\n", strings.ToLower(fsmClassNode.ClassName))
+	cImplementor.Output += fmt.Sprintf(
+		"static void handle_event(struct %s *fsm, enum %s event);\n\n",
+		cImplementor.className, cImplementor.enumName("Event"),
+	)
+
+	fsmClassNode.StateEnum.Accept(cImplementor)
+	fsmClassNode.EventEnum.Accept(cImplementor)
+
+	cImplementor.Header += fmt.Sprintf("struct %s {\n", cImplementor.actionsName)
+	for _, action := range fsmClassNode.Actions {
+		cImplementor.Header += fmt.Sprintf("void (*%s)(void *context);\n", action)
+	}
+	cImplementor.Header += "/* Optional, may be NULL. */\n"
+	cImplementor.Header += fmt.Sprintf(
+		"void (*unhandledTransition)(void *context, enum %s state, enum %s event);\n",
+		cImplementor.enumName("State"), cImplementor.enumName("Event"),
+	)
+	cImplementor.Header += "};\n\n"
+
+	cImplementor.Header += fmt.Sprintf("struct %s {\n", cImplementor.className)
+	cImplementor.Header += fmt.Sprintf("enum %s state;\n", cImplementor.enumName("State"))
+	cImplementor.Header += fmt.Sprintf("const struct %s *actions;\n", cImplementor.actionsName)
+	cImplementor.Header += "void *context;\n"
+	cImplementor.Header += "};\n\n"
+
+	fsmClassNode.StateProperty.Accept(cImplementor)
+	fsmClassNode.Delegators.Accept(cImplementor)
+	fsmClassNode.HandleEvent.Accept(cImplementor)
+
+	cImplementor.Header += fmt.Sprintf("\n#endif /* %s */\n", guard)
+}
+
+func (cImplementor *CNestedSwitchCaseImplementor) VisitHandleEventNode(
+	handleEventNode *nscgenerator.HandleEventNode,
+) {
+	cImplementor.Output += fmt.Sprintf(
+		"static void handle_event(struct %s *fsm, enum %s event) {\n",
+		cImplementor.className, cImplementor.enumName("Event"),
+	)
+	handleEventNode.SwitchCase.Accept(cImplementor)
+	cImplementor.Output += "}\n"
+}
+
+func (cImplementor *CNestedSwitchCaseImplementor) VisitEnumeratorNode(
+	enumeratorNode *nscgenerator.EnumeratorNode,
+) {
+	cImplementor.Output += cImplementor.enumerator(enumeratorNode.Enumeration, enumeratorNode.Enumerator)
+}
+
+func (cImplementor *CNestedSwitchCaseImplementor) VisitDefaultCaseNode(
+	defaultCaseNode *nscgenerator.DefaultCaseNode,
+) {
+	cImplementor.Output += "default:\n"
+	cImplementor.Output += "if (fsm->actions->unhandledTransition != NULL) {\n"
+	cImplementor.Output += "fsm->actions->unhandledTransition(fsm->context, fsm->state, event);\n"
+	cImplementor.Output += "}\n"
+	cImplementor.Output += "break;\n"
+}
+
+func (cImplementor *CNestedSwitchCaseImplementor) enumName(enumeration string) string {
+	return cImplementor.className + "_" + enumeration
+}
+
+func (cImplementor *CNestedSwitchCaseImplementor) enumerator(enumeration, enumerator string) string {
+	return cImplementor.enumName(enumeration) + "_" + enumerator
+}
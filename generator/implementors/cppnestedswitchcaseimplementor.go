@@ -0,0 +1,214 @@
+package implementors
+
+import (
+	"fmt"
+	"strings"
+
+	nscgenerator "github.com/larkvincer/dsl-fsm/generator/nestedswitchcasegenerator"
+)
+
+// CppNestedSwitchCaseImplementor writes a header into Header and the
+// matching implementation into Output. The dispatch flag selects how actions
+// are called: "virtual" (the default) declares them pure virtual, "crtp"
+// turns the machine into a class template that calls them on its Derived
+// parameter, so they can be inlined. A class template has to be defined in
+// the header, so in crtp mode Output only holds the enum name functions.
+type CppNestedSwitchCaseImplementor struct {
+	Output       string
+	Header       string
+	flags        map[string]string
+	cppNamespace string
+	crtp         bool
+	className    string
+	code         *string
+}
+
+func NewCppNestedSwitchCaseImplementor(flags map[string]string) *CppNestedSwitchCaseImplementor {
+	obj := &CppNestedSwitchCaseImplementor{
+		flags: flags,
+	}
+	if _, ok := flags["namespace"]; ok {
+		obj.cppNamespace = flags["namespace"]
+	}
+	if dispatch, ok := flags["dispatch"]; ok && strings.EqualFold(dispatch, "crtp") {
+		obj.crtp = true
+	}
+	obj.code = &obj.Output
+	if obj.crtp {
+		obj.code = &obj.Header
+	}
+	return obj
+}
+
+func (cppImplementor *CppNestedSwitchCaseImplementor) VisitSwitchCaseNode(
+	switchCaseNode *nscgenerator.SwitchCaseNode,
+) {
+	*cppImplementor.code += fmt.Sprintf("switch (%s) {\n", switchCaseNode.VariableName)
+	switchCaseNode.GenerateCases(cppImplementor)
+	*cppImplementor.code += "}\n"
+}
+
+func (cppImplementor *CppNestedSwitchCaseImplementor) VisitCaseNode(caseNode *nscgenerator.CaseNode) {
+	*cppImplementor.code += fmt.Sprintf("case %s::%s:\n", caseNode.SwitchName, caseNode.CaseName)
+	caseNode.CaseActionNode.Accept(cppImplementor)
+	*cppImplementor.code += "break;\n"
+}
+
+func (cppImplementor *CppNestedSwitchCaseImplementor) VisitFunctionalCallNode(
+	functionCallNode *nscgenerator.FunctionCallNode,
+) {
+	if functionCallNode.Argument == nil && cppImplementor.crtp {
+		*cppImplementor.code += "derived()."
+	}
+	*cppImplementor.code += fmt.Sprintf("%s(", functionCallNode.FunctionName)
+	if functionCallNode.Argument != nil {
+		functionCallNode.Argument.Accept(cppImplementor)
+	}
+	*cppImplementor.code += ");\n"
+}
+
+func (cppImplementor *CppNestedSwitchCaseImplementor) VisitEnumNode(enumNode *nscgenerator.EnumNode) {
+	enumName := cppImplementor.className + enumNode.Name
+	cppImplementor.Header += fmt.Sprintf("enum class %s {\n", enumName)
+	for _, enumerator := range enumNode.Enumerators {
+		cppImplementor.Header += enumerator + ",\n"
+	}
+	cppImplementor.Header += "};\n\n"
+	cppImplementor.Header += fmt.Sprintf("const char *toString(%s value);\n\n", enumName)
+
+	cppImplementor.Output += fmt.Sprintf("const char *toString(%s value) {\n", enumName)
+	cppImplementor.Output += "switch (value) {\n"
+	for _, enumerator := range enumNode.Enumerators {
+		cppImplementor.Output += fmt.Sprintf("case %s::%s: return \"%s\";\n", enumName, enumerator, enumerator)
+	}
+	cppImplementor.Output += "}\n"
+	cppImplementor.Output += "return \"?\";\n"
+	cppImplementor.Output += "}\n\n"
+}
+
+func (cppImplementor *CppNestedSwitchCaseImplementor) VisitStatePropertyNode(
+	statePropertyNode *nscgenerator.StatePropertyNode,
+) {
+	cppImplementor.Header += fmt.Sprintf("State state = State::%s;\n\n", statePropertyNode.InitialState)
+	cppImplementor.Header += "void setState(State s) { state = s; }\n\n"
+}
+
+func (cppImplementor *CppNestedSwitchCaseImplementor) VisitEventDelegatorsNode(
+	eventDelegatorsNode *nscgenerator.EventDelegatorsNode,
+) {
+	for _, event := range eventDelegatorsNode.Events {
+		if cppImplementor.crtp {
+			cppImplementor.Header += fmt.Sprintf("void %s() { handleEvent(Event::%s); }\n", event, event)
+		} else {
+			cppImplementor.Header += fmt.Sprintf("void %s();\n", event)
+			cppImplementor.Output += fmt.Sprintf(
+				"void %s::%s() {\nhandleEvent(Event::%s);\n}\n\n", cppImplementor.className, event, event,
+			)
+		}
+	}
+}
+
+func (cppImplementor *CppNestedSwitchCaseImplementor) VisitFSMClassNode(fsmClassNode *nscgenerator.FSMClassNode) {
+	cppImplementor.className = fsmClassNode.ClassName
+	guard := strings.ToUpper(fsmClassNode.ClassName) + "_HPP"
+
+	cppImplementor.Header += "// Generated by smc. Do not edit.\n"
+	cppImplementor.Header += fmt.Sprintf("#ifndef %s\n#define %s\n\n", guard, guard)
+	cppImplementor.Output += "// Generated by smc. Do not edit.\n"
+	cppImplementor.Output += fmt.Sprintf("#include \"%s.hpp\"\n\n", strings.ToLower(fsmClassNode.ClassName))
+	if cppImplementor.cppNamespace != "" {
+		cppImplementor.Header += fmt.Sprintf("namespace %s {\n\n", cppImplementor.cppNamespace)
+		cppImplementor.Output += fmt.Sprintf("namespace %s {\n\n", cppImplementor.cppNamespace)
+	}
+
+	fsmClassNode.StateEnum.Accept(cppImplementor)
+	fsmClassNode.EventEnum.Accept(cppImplementor)
+
+	actionsInClass := true
+	baseClass := ""
+	if !cppImplementor.crtp && fsmClassNode.ActionsName != "" {
+		cppImplementor.Header += fmt.Sprintf(
+			"class %s {\npublic:\nvirtual ~%s() = default;\n\n", fsmClassNode.ActionsName, fsmClassNode.ActionsName,
+		)
+		cppImplementor.pureVirtualActions(fsmClassNode.Actions)
+		cppImplementor.Header += "};\n\n"
+		actionsInClass = false
+		baseClass = " : public " + fsmClassNode.ActionsName
+	}
+
+	if cppImplementor.crtp {
+		cppImplementor.Header += "// Derived implements the actions and\n"
+		cppImplementor.Header += "// void unhandledTransition(State state, Event event). They must be public,\n"
+		cppImplementor.Header += "// or Derived must befriend this class.\n"
+		cppImplementor.Header += "template <typename Derived>\n"
+	}
+	cppImplementor.Header += fmt.Sprintf("class %s%s {\npublic:\n", fsmClassNode.ClassName, baseClass)
+	cppImplementor.Header += fmt.Sprintf("using State = %sState;\n", fsmClassNode.ClassName)
+	cppImplementor.Header += fmt.Sprintf("using Event = %sEvent;\n\n", fsmClassNode.ClassName)
+	if !cppImplementor.crtp {
+		cppImplementor.Header += fmt.Sprintf("virtual ~%s() = default;\n\n", fsmClassNode.ClassName)
+	}
+	cppImplementor.Header += "State getState() const { return state; }\n\n"
+	fsmClassNode.Delegators.Accept(cppImplementor)
+
+	if !cppImplementor.crtp {
+		cppImplementor.Header += "\nprotected:\n"
+		cppImplementor.Header += "virtual void unhandledTransition(State state, Event event) = 0;\n"
+		if actionsInClass {
+			cppImplementor.pureVirtualActions(fsmClassNode.Actions)
+		}
+	}
+
+	cppImplementor.Header += "\nprivate:\n"
+	fsmClassNode.StateProperty.Accept(cppImplementor)
+	if cppImplementor.crtp {
+		cppImplementor.Header += "Derived &derived() { return static_cast<Derived &>(*this); }\n\n"
+	} else {
+		cppImplementor.Header += "void handleEvent(Event event);\n"
+	}
+	fsmClassNode.HandleEvent.Accept(cppImplementor)
+	cppImplementor.Header += "};\n"
+
+	if cppImplementor.cppNamespace != "" {
+		cppImplementor.Header += fmt.Sprintf("\n} // namespace %s\n", cppImplementor.cppNamespace)
+		cppImplementor.Output += fmt.Sprintf("} // namespace %s\n", cppImplementor.cppNamespace)
+	}
+	cppImplementor.Header += fmt.Sprintf("\n#endif // %s\n", guard)
+}
+
+func (cppImplementor *CppNestedSwitchCaseImplementor) VisitHandleEventNode(
+	handleEventNode *nscgenerator.HandleEventNode,
+) {
+	if cppImplementor.crtp {
+		*cppImplementor.code += "void handleEvent(Event event) {\n"
+	} else {
+		*cppImplementor.code += fmt.Sprintf("void %s::handleEvent(Event event) {\n", cppImplementor.className)
+	}
+	handleEventNode.SwitchCase.Accept(cppImplementor)
+	*cppImplementor.code += "}\n"
+	if !cppImplementor.crtp {
+		*cppImplementor.code += "\n"
+	}
+}
+
+func (cppImplementor *CppNestedSwitchCaseImplementor) VisitEnumeratorNode(
+	enumeratorNode *nscgenerator.EnumeratorNode,
+) {
+	*cppImplementor.code += fmt.Sprintf("%s::%s", enumeratorNode.Enumeration, enumeratorNode.Enumerator)
+}
+
+func (cppImplementor *CppNestedSwitchCaseImplementor) VisitDefaultCaseNode(
+	defaultCaseNode *nscgenerator.DefaultCaseNode,
+) {
+	*cppImplementor.code += "default:\n"
+	if cppImplementor.crtp {
+		*cppImplementor.code += "derived()."
+	}
+	*cppImplementor.code += "unhandledTransition(state, event);\nbreak;\n"
+}
+
+func (cppImplementor *CppNestedSwitchCaseImplementor) pureVirtualActions(actions []string) {
+	for _, action := range actions {
+		cppImplementor.Header += fmt.Sprintf("virtual void %s() = 0;\n", action)
+	}
+}
@@ -0,0 +1,127 @@
+package implementors
+
+import (
+	"fmt"
+
+	nscgenerator "github.com/larkvincer/dsl-fsm/generator/nestedswitchcasegenerator"
+)
+
+// CSharpNestedSwitchCaseImplementor emits an abstract partial class, so
+// the actions can be implemented in another part of the same class as well
+// as in a subclass. Methods follow the .NET PascalCase convention.
+type CSharpNestedSwitchCaseImplementor struct {
+	Output          string
+	flags           map[string]string
+	csharpNamespace string
+}
+
+func NewCSharpNestedSwitchCaseImplementor(flags map[string]string) *CSharpNestedSwitchCaseImplementor {
+	obj := &CSharpNestedSwitchCaseImplementor{
+		flags: flags,
+	}
+	if _, ok := flags["namespace"]; ok {
+		obj.csharpNamespace = flags["namespace"]
+	}
+	return obj
+}
+
+func (csharpImplementor *CSharpNestedSwitchCaseImplementor) VisitSwitchCaseNode(
+	switchCaseNode *nscgenerator.SwitchCaseNode,
+) {
+	variableName := switchCaseNode.VariableName
+	if variableName == "event" {
+		variableName = "evt"
+	}
+	csharpImplementor.Output += fmt.Sprintf("switch (%s)\n{\n", variableName)
+	switchCaseNode.GenerateCases(csharpImplementor)
+	csharpImplementor.Output += "}\n"
+}
+
+func (csharpImplementor *CSharpNestedSwitchCaseImplementor) VisitCaseNode(caseNode *nscgenerator.CaseNode) {
+	csharpImplementor.Output += fmt.Sprintf("case %s.%s:\n", caseNode.SwitchName, caseNode.CaseName)
+	caseNode.CaseActionNode.Accept(csharpImplementor)
+	csharpImplementor.Output += "break;\n"
+}
+
+func (csharpImplementor *CSharpNestedSwitchCaseImplementor) VisitFunctionalCallNode(
+	functionCallNode *nscgenerator.FunctionCallNode,
+) {
+	csharpImplementor.Output += fmt.Sprintf("%s(", Capitalize(functionCallNode.FunctionName))
+	if functionCallNode.Argument != nil {
+		functionCallNode.Argument.Accept(csharpImplementor)
+	}
+	csharpImplementor.Output += ");\n"
+}
+
+func (csharpImplementor *CSharpNestedSwitchCaseImplementor) VisitEnumNode(enumNode *nscgenerator.EnumNode) {
+	csharpImplementor.Output += fmt.Sprintf("public enum %s\n{\n", enumNode.Name)
+	for _, enumerator := range enumNode.Enumerators {
+		csharpImplementor.Output += enumerator + ",\n"
+	}
+	csharpImplementor.Output += "}\n\n"
+}
+
+func (csharpImplementor *CSharpNestedSwitchCaseImplementor) VisitStatePropertyNode(
+	statePropertyNode *nscgenerator.StatePropertyNode,
+) {
+	csharpImplementor.Output += fmt.Sprintf("private State state = State.%s;\n\n", statePropertyNode.InitialState)
+	csharpImplementor.Output += "public State CurrentState => state;\n\n"
+	csharpImplementor.Output += "private void SetState(State s)\n{\nstate = s;\n}\n\n"
+}
+
+func (csharpImplementor *CSharpNestedSwitchCaseImplementor) VisitEventDelegatorsNode(
+	eventDelegatorsNode *nscgenerator.EventDelegatorsNode,
+) {
+	for _, event := range eventDelegatorsNode.Events {
+		csharpImplementor.Output += fmt.Sprintf(
+			"public void %s()\n{\nHandleEvent(Event.%s);\n}\n\n", Capitalize(event), event,
+		)
+	}
+}
+
+func (csharpImplementor *CSharpNestedSwitchCaseImplementor) VisitFSMClassNode(fsmClassNode *nscgenerator.FSMClassNode) {
+	csharpImplementor.Output += "// Generated by smc. Do not edit.\n"
+	if csharpImplementor.csharpNamespace != "" {
+		csharpImplementor.Output += fmt.Sprintf("namespace %s\n{\n", csharpImplementor.csharpNamespace)
+	}
+
+	csharpImplementor.Output += fmt.Sprintf("public abstract partial class %s\n{\n", fsmClassNode.ClassName)
+	fsmClassNode.StateEnum.Accept(csharpImplementor)
+	fsmClassNode.EventEnum.Accept(csharpImplementor)
+	fsmClassNode.StateProperty.Accept(csharpImplementor)
+	fsmClassNode.Delegators.Accept(csharpImplementor)
+	fsmClassNode.HandleEvent.Accept(csharpImplementor)
+
+	csharpImplementor.Output += "\nprotected virtual void UnhandledTransition(string state, string evt)\n{\n}\n"
+	if len(fsmClassNode.Actions) > 0 {
+		csharpImplementor.Output += "\n"
+	}
+	for _, action := range fsmClassNode.Actions {
+		csharpImplementor.Output += fmt.Sprintf("protected abstract void %s();\n", Capitalize(action))
+	}
+	csharpImplementor.Output += "}\n"
+
+	if csharpImplementor.csharpNamespace != "" {
+		csharpImplementor.Output += "}\n"
+	}
+}
+
+func (csharpImplementor *CSharpNestedSwitchCaseImplementor) VisitHandleEventNode(
+	handleEventNode *nscgenerator.HandleEventNode,
+) {
+	csharpImplementor.Output += "private void HandleEvent(Event evt)\n{\n"
+	handleEventNode.SwitchCase.Accept(csharpImplementor)
+	csharpImplementor.Output += "}\n"
+}
+
+func (csharpImplementor *CSharpNestedSwitchCaseImplementor) VisitEnumeratorNode(
+	enumeratorNode *nscgenerator.EnumeratorNode,
+) {
+	csharpImplementor.Output += fmt.Sprintf("%s.%s", enumeratorNode.Enumeration, enumeratorNode.Enumerator)
+}
+
+func (csharpImplementor *CSharpNestedSwitchCaseImplementor) VisitDefaultCaseNode(
+	defaultCaseNode *nscgenerator.DefaultCaseNode,
+) {
+	csharpImplementor.Output += "default:\nUnhandledTransition(state.ToString(), evt.ToString());\nbreak;\n"
+}
@@ -0,0 +1,174 @@
+package implementors
+
+import (
+	"fmt"
+	"strings"
+
+	nscgenerator "github.com/larkvincer/dsl-fsm/generator/nestedswitchcasegenerator"
+)
+
+type GoNestedSwitchCaseImplementor struct {
+	Output      string
+	flags       map[string]string
+	goPackage   string
+	className   string
+	actionsName string
+}
+
+func NewGoNestedSwitchCaseImplementor(flags map[string]string) *GoNestedSwitchCaseImplementor {
+	obj := &GoNestedSwitchCaseImplementor{
+		flags: flags,
+	}
+	if _, ok := flags["package"]; ok {
+		obj.goPackage = flags["package"]
+	}
+	return obj
+}
+
+func (goImplementor *GoNestedSwitchCaseImplementor) GetPackage() string {
+	return goImplementor.goPackage
+}
+
+func (goImplementor *GoNestedSwitchCaseImplementor) VisitSwitchCaseNode(
+	switchCaseNode *nscgenerator.SwitchCaseNode,
+) {
+	variableName := switchCaseNode.VariableName
+	if variableName == "state" {
+		variableName = "fsm.state"
+	}
+	goImplementor.Output += fmt.Sprintf("switch %s {\n", variableName)
+	switchCaseNode.GenerateCases(goImplementor)
+	goImplementor.Output += "}\n"
+}
+
+func (goImplementor *GoNestedSwitchCaseImplementor) VisitCaseNode(caseNode *nscgenerator.CaseNode) {
+	goImplementor.Output += fmt.Sprintf("case %s%s:\n", caseNode.SwitchName, Capitalize(caseNode.CaseName))
+	caseNode.CaseActionNode.Accept(goImplementor)
+}
+
+func (goImplementor *GoNestedSwitchCaseImplementor) VisitFunctionalCallNode(
+	functionCallNode *nscgenerator.FunctionCallNode,
+) {
+	if functionCallNode.Argument != nil {
+		goImplementor.Output += fmt.Sprintf("fsm.%s(", functionCallNode.FunctionName)
+		functionCallNode.Argument.Accept(goImplementor)
+	} else {
+		goImplementor.Output += fmt.Sprintf("fsm.actions.%s(", Capitalize(functionCallNode.FunctionName))
+	}
+	goImplementor.Output += ")\n"
+}
+
+func (goImplementor *GoNestedSwitchCaseImplementor) VisitEnumNode(enumNode *nscgenerator.EnumNode) {
+	goImplementor.Output += fmt.Sprintf("type %s int\n\n", enumNode.Name)
+	goImplementor.Output += "const (\n"
+	for i, enumerator := range enumNode.Enumerators {
+		if i == 0 {
+			goImplementor.Output += fmt.Sprintf("%s%s %s = iota\n", enumNode.Name, Capitalize(enumerator), enumNode.Name)
+		} else {
+			goImplementor.Output += fmt.Sprintf("%s%s\n", enumNode.Name, Capitalize(enumerator))
+		}
+	}
+	goImplementor.Output += ")\n\n"
+
+	receiver := strings.ToLower(enumNode.Name[:1])
+	goImplementor.Output += fmt.Sprintf("func (%s %s) String() string {\n", receiver, enumNode.Name)
+	goImplementor.Output += fmt.Sprintf("switch %s {\n", receiver)
+	for _, enumerator := range enumNode.Enumerators {
+		goImplementor.Output += fmt.Sprintf("case %s%s:\n", enumNode.Name, Capitalize(enumerator))
+		goImplementor.Output += fmt.Sprintf("return %q\n", enumerator)
+	}
+	goImplementor.Output += "}\n"
+	goImplementor.Output += fmt.Sprintf("return fmt.Sprintf(\"%s(%%d)\", int(%s))\n", enumNode.Name, receiver)
+	goImplementor.Output += "}\n\n"
+}
+
+func (goImplementor *GoNestedSwitchCaseImplementor) VisitStatePropertyNode(
+	statePropertyNode *nscgenerator.StatePropertyNode,
+) {
+	goImplementor.Output += fmt.Sprintf(
+		"func New%s(actions %s) *%s {\n",
+		Capitalize(goImplementor.className), goImplementor.actionsName, goImplementor.className,
+	)
+	goImplementor.Output += fmt.Sprintf(
+		"return &%s{actions: actions, state: State%s}\n",
+		goImplementor.className, Capitalize(statePropertyNode.InitialState),
+	)
+	goImplementor.Output += "}\n\n"
+	goImplementor.Output += fmt.Sprintf(
+		"func (fsm *%s) CurrentState() State { return fsm.state }\n\n", goImplementor.className,
+	)
+	goImplementor.Output += fmt.Sprintf(
+		"func (fsm *%s) setState(s State) { fsm.state = s }\n\n", goImplementor.className,
+	)
+}
+
+func (goImplementor *GoNestedSwitchCaseImplementor) VisitEventDelegatorsNode(
+	eventDelegatorsNode *nscgenerator.EventDelegatorsNode,
+) {
+	for _, event := range eventDelegatorsNode.Events {
+		goImplementor.Output += fmt.Sprintf(
+			"func (fsm *%s) %s() { fsm.handleEvent(Event%s) }\n\n",
+			goImplementor.className, Capitalize(event), Capitalize(event),
+		)
+	}
+}
+
+func (goImplementor *GoNestedSwitchCaseImplementor) VisitFSMClassNode(fsmClassNode *nscgenerator.FSMClassNode) {
+	goImplementor.className = fsmClassNode.ClassName
+	goImplementor.actionsName = fsmClassNode.ActionsName
+	if goImplementor.actionsName == "" {
+		goImplementor.actionsName = fsmClassNode.ClassName + "Actions"
+	}
+	if goImplementor.goPackage == "" {
+		goImplementor.goPackage = strings.ToLower(fsmClassNode.ClassName)
+	}
+
+	goImplementor.Output += "// Code generated by smc. DO NOT EDIT.\n\n"
+	goImplementor.Output += fmt.Sprintf("package %s\n\n", goImplementor.goPackage)
+	goImplementor.Output += "import \"fmt\"\n\n"
+	fsmClassNode.StateEnum.Accept(goImplementor)
+	fsmClassNode.EventEnum.Accept(goImplementor)
+
+	goImplementor.Output += fmt.Sprintf("type %s interface {\n", goImplementor.actionsName)
+	for _, action := range fsmClassNode.Actions {
+		goImplementor.Output += fmt.Sprintf("%s()\n", Capitalize(action))
+	}
+	goImplementor.Output += "}\n\n"
+
+	goImplementor.Output += fmt.Sprintf("type %s struct {\n", goImplementor.className)
+	goImplementor.Output += "// UnhandledTransition is called, when set, for events the current state does not handle.\n"
+	goImplementor.Output += "UnhandledTransition func(state State, event Event)\n"
+	goImplementor.Output += fmt.Sprintf("actions %s\n", goImplementor.actionsName)
+	goImplementor.Output += "state State\n"
+	goImplementor.Output += "}\n\n"
+
+	fsmClassNode.StateProperty.Accept(goImplementor)
+	fsmClassNode.Delegators.Accept(goImplementor)
+	fsmClassNode.HandleEvent.Accept(goImplementor)
+
+	goImplementor.Output += fmt.Sprintf("func (fsm *%s) unhandledTransition(event Event) {\n", goImplementor.className)
+	goImplementor.Output += "if fsm.UnhandledTransition != nil {\n"
+	goImplementor.Output += "fsm.UnhandledTransition(fsm.state, event)\n"
+	goImplementor.Output += "}\n"
+	goImplementor.Output += "}\n"
+}
+
+func (goImplementor *GoNestedSwitchCaseImplementor) VisitHandleEventNode(
+	handleEventNode *nscgenerator.HandleEventNode,
+) {
+	goImplementor.Output += fmt.Sprintf("func (fsm *%s) handleEvent(event Event) {\n", goImplementor.className)
+	handleEventNode.SwitchCase.Accept(goImplementor)
+	goImplementor.Output += "}\n\n"
+}
+
+func (goImplementor *GoNestedSwitchCaseImplementor) VisitEnumeratorNode(
+	enumeratorNode *nscgenerator.EnumeratorNode,
+) {
+	goImplementor.Output += fmt.Sprintf("%s%s", enumeratorNode.Enumeration, Capitalize(enumeratorNode.Enumerator))
+}
+
+func (goImplementor *GoNestedSwitchCaseImplementor) VisitDefaultCaseNode(
+	defaultCaseNode *nscgenerator.DefaultCaseNode,
+) {
+	goImplementor.Output += "default:\nfsm.unhandledTransition(event)\n"
+}
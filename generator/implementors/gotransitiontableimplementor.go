@@ -0,0 +1,117 @@
+package implementors
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	nscgenerator "github.com/larkvincer/dsl-fsm/generator/nestedswitchcasegenerator"
+	ttgenerator "github.com/larkvincer/dsl-fsm/generator/transitiontablegenerator"
+)
+
+// GoTransitionTableImplementor generates the same API as the nested
+// switch/case implementor, but handleEvent looks the transition up in
+// package level tables instead of switching on the state and event.
+type GoTransitionTableImplementor struct {
+	GoNestedSwitchCaseImplementor
+}
+
+func NewGoTransitionTableImplementor(flags map[string]string) *GoTransitionTableImplementor {
+	return &GoTransitionTableImplementor{
+		GoNestedSwitchCaseImplementor: *NewGoNestedSwitchCaseImplementor(flags),
+	}
+}
+
+func (goImplementor *GoTransitionTableImplementor) Implement(table *ttgenerator.TransitionTable) {
+	goImplementor.className = table.ClassName
+	goImplementor.actionsName = table.ActionsName
+	if goImplementor.actionsName == "" {
+		goImplementor.actionsName = table.ClassName + "Actions"
+	}
+	if goImplementor.goPackage == "" {
+		goImplementor.goPackage = strings.ToLower(table.ClassName)
+	}
+	tablePrefix := uncapitalize(table.ClassName)
+
+	goImplementor.Output += "// Code generated by smc. DO NOT EDIT.\n\n"
+	goImplementor.Output += fmt.Sprintf("package %s\n\n", goImplementor.goPackage)
+	goImplementor.Output += "import \"fmt\"\n\n"
+	goImplementor.VisitEnumNode(nscgenerator.NewEnumNode("State", table.States))
+	goImplementor.VisitEnumNode(nscgenerator.NewEnumNode("Event", table.Events))
+
+	goImplementor.Output += fmt.Sprintf("type %s interface {\n", goImplementor.actionsName)
+	for _, action := range table.Actions {
+		goImplementor.Output += fmt.Sprintf("%s()\n", Capitalize(action))
+	}
+	goImplementor.Output += "}\n\n"
+
+	goImplementor.Output += fmt.Sprintf(
+		"// %sNextStates[state][event] is the next state, or %d when the event is unhandled.\n",
+		tablePrefix, ttgenerator.Unhandled,
+	)
+	goImplementor.Output += fmt.Sprintf(
+		"var %sNextStates = [%d][%d]int%s\n\n", tablePrefix, len(table.States), len(table.Events), intTable(table.NextStates),
+	)
+	goImplementor.Output += fmt.Sprintf(
+		"// %sActionListIndices[state][event] indexes %sActionLists.\n", tablePrefix, tablePrefix,
+	)
+	goImplementor.Output += fmt.Sprintf(
+		"var %sActionListIndices = [%d][%d]int%s\n\n",
+		tablePrefix, len(table.States), len(table.Events), intTable(table.ActionListIndices),
+	)
+	goImplementor.Output += fmt.Sprintf("var %sActionLists = [][]int%s\n\n", tablePrefix, intTable(table.ActionLists))
+	goImplementor.Output += fmt.Sprintf("var %sActions = [...]func(%s){\n", tablePrefix, goImplementor.actionsName)
+	for _, action := range table.Actions {
+		goImplementor.Output += fmt.Sprintf("%s.%s,\n", goImplementor.actionsName, Capitalize(action))
+	}
+	goImplementor.Output += "}\n\n"
+
+	goImplementor.Output += fmt.Sprintf("type %s struct {\n", goImplementor.className)
+	goImplementor.Output += "// UnhandledTransition is called, when set, for events the current state does not handle.\n"
+	goImplementor.Output += "UnhandledTransition func(state State, event Event)\n"
+	goImplementor.Output += fmt.Sprintf("actions %s\n", goImplementor.actionsName)
+	goImplementor.Output += "state State\n"
+	goImplementor.Output += "}\n\n"
+
+	goImplementor.VisitStatePropertyNode(nscgenerator.NewStatePropertyNode(table.States[table.InitialState]))
+	goImplementor.VisitEventDelegatorsNode(nscgenerator.NewEventDelegatorsNode(table.Events))
+
+	goImplementor.Output += fmt.Sprintf("func (fsm *%s) handleEvent(event Event) {\n", goImplementor.className)
+	goImplementor.Output += fmt.Sprintf("next := %sNextStates[fsm.state][event]\n", tablePrefix)
+	goImplementor.Output += fmt.Sprintf("if next == %d {\n", ttgenerator.Unhandled)
+	goImplementor.Output += "fsm.unhandledTransition(event)\n"
+	goImplementor.Output += "return\n"
+	goImplementor.Output += "}\n"
+	goImplementor.Output += fmt.Sprintf(
+		"actionList := %sActionLists[%sActionListIndices[fsm.state][event]]\n", tablePrefix, tablePrefix,
+	)
+	goImplementor.Output += "fsm.setState(State(next))\n"
+	goImplementor.Output += "for _, action := range actionList {\n"
+	goImplementor.Output += fmt.Sprintf("%sActions[action](fsm.actions)\n", tablePrefix)
+	goImplementor.Output += "}\n"
+	goImplementor.Output += "}\n\n"
+
+	goImplementor.Output += fmt.Sprintf("func (fsm *%s) unhandledTransition(event Event) {\n", goImplementor.className)
+	goImplementor.Output += "if fsm.UnhandledTransition != nil {\n"
+	goImplementor.Output += "fsm.UnhandledTransition(fsm.state, event)\n"
+	goImplementor.Output += "}\n"
+	goImplementor.Output += "}\n"
+}
+
+// intTable formats rows of ints as a brace initializer, which Go and Java
+// share.
+func intTable(rows [][]int) string {
+	table := "{\n"
+	for _, row := range rows {
+		table += intList(row) + ",\n"
+	}
+	return table + "}"
+}
+
+func intList(values []int) string {
+	items := make([]string, 0, len(values))
+	for _, value := range values {
+		items = append(items, strconv.Itoa(value))
+	}
+	return "{" + strings.Join(items, ", ") + "}"
+}
@@ -0,0 +1,42 @@
+package implementors
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// Capitalize upper-cases the first letter of name, the way the generators
+// that follow PascalCase conventions name methods and enumerators.
+func Capitalize(name string) string {
+	first, size := utf8.DecodeRuneInString(name)
+	if first == utf8.RuneError {
+		return name
+	}
+	return string(unicode.ToUpper(first)) + name[size:]
+}
+
+func uncapitalize(name string) string {
+	first, size := utf8.DecodeRuneInString(name)
+	if first == utf8.RuneError {
+		return name
+	}
+	return string(unicode.ToLower(first)) + name[size:]
+}
+
+// SnakeCase converts camelCase and PascalCase names, keeping acronyms
+// together: "alarmOn" becomes "alarm_on" and "HTTPRequest" "http_request".
+func SnakeCase(name string) string {
+	runes := []rune(name)
+	snake := make([]rune, 0, len(runes)+4)
+	for i, r := range runes {
+		if unicode.IsUpper(r) && i > 0 && runes[i-1] != '_' {
+			previousIsLower := unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1])
+			nextIsLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if previousIsLower || (unicode.IsUpper(runes[i-1]) && nextIsLower) {
+				snake = append(snake, '_')
+			}
+		}
+		snake = append(snake, unicode.ToLower(r))
+	}
+	return string(snake)
+}
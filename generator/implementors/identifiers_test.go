@@ -0,0 +1,24 @@
+package implementors
+
+import "testing"
+
+func TestSnakeCase(t *testing.T) {
+	for name, expected := range map[string]string{
+		"alarmOn":      "alarm_on",
+		"FirstCoin":    "first_coin",
+		"HTTPRequest":  "http_request",
+		"lock":         "lock",
+		"already_done": "already_done",
+		"state2Ready":  "state2_ready",
+	} {
+		if got := SnakeCase(name); got != expected {
+			t.Errorf("SnakeCase(%q): expected %q, but got %q", name, expected, got)
+		}
+	}
+}
+
+func TestCapitalize(t *testing.T) {
+	if Capitalize("locked") != "Locked" || Capitalize("") != "" {
+		t.Fatal("capitalize does not upper-case the first letter")
+	}
+}
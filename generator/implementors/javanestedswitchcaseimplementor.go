@@ -22,6 +22,10 @@ func NewJavaNestedSwitchCaseImplementor(flags map[string]string) *JavaNestedSwit
 	return obj
 }
 
+func (javaImplementor *JavaNestedSwitchCaseImplementor) GetPackage() string {
+	return javaImplementor.javaPackage
+}
+
 func (javaImplementor *JavaNestedSwitchCaseImplementor) VisitSwitchCaseNode(
 	switchCaseNode *nscgenerator.SwitchCaseNode,
 ) {
@@ -0,0 +1,78 @@
+package implementors
+
+import (
+	"fmt"
+
+	nscgenerator "github.com/larkvincer/dsl-fsm/generator/nestedswitchcasegenerator"
+	ttgenerator "github.com/larkvincer/dsl-fsm/generator/transitiontablegenerator"
+)
+
+// JavaTransitionTableImplementor generates the same class as the nested
+// switch/case implementor, but handleEvent looks the transition up in static
+// tables indexed by the enum ordinals.
+type JavaTransitionTableImplementor struct {
+	JavaNestedSwitchCaseImplementor
+}
+
+func NewJavaTransitionTableImplementor(flags map[string]string) *JavaTransitionTableImplementor {
+	return &JavaTransitionTableImplementor{
+		JavaNestedSwitchCaseImplementor: *NewJavaNestedSwitchCaseImplementor(flags),
+	}
+}
+
+func (javaImplementor *JavaTransitionTableImplementor) Implement(table *ttgenerator.TransitionTable) {
+	if javaImplementor.javaPackage != "" {
+		javaImplementor.Output += "package " + javaImplementor.javaPackage + ";\n"
+	}
+
+	if table.ActionsName != "" {
+		javaImplementor.Output += fmt.Sprintf(
+			"public abstract class %s implements %s {\n", table.ClassName, table.ActionsName,
+		)
+	} else {
+		javaImplementor.Output += fmt.Sprintf("public abstract class %s {\n", table.ClassName)
+	}
+
+	javaImplementor.Output += "public abstract void unhandledTransition(String state, String event);\n"
+	javaImplementor.VisitEnumNode(nscgenerator.NewEnumNode("State", table.States))
+	javaImplementor.VisitEnumNode(nscgenerator.NewEnumNode("Event", table.Events))
+	javaImplementor.Output += fmt.Sprintf(
+		"// NEXT_STATES[state][event] is the next state, or %d when the event is unhandled.\n", ttgenerator.Unhandled,
+	)
+	javaImplementor.Output += fmt.Sprintf("private static final int[][] NEXT_STATES = %s;\n", intTable(table.NextStates))
+	javaImplementor.Output += "// ACTION_LIST_INDICES[state][event] indexes ACTION_LISTS.\n"
+	javaImplementor.Output += fmt.Sprintf(
+		"private static final int[][] ACTION_LIST_INDICES = %s;\n", intTable(table.ActionListIndices),
+	)
+	javaImplementor.Output += fmt.Sprintf("private static final int[][] ACTION_LISTS = %s;\n", intTable(table.ActionLists))
+	javaImplementor.VisitStatePropertyNode(nscgenerator.NewStatePropertyNode(table.States[table.InitialState]))
+	javaImplementor.VisitEventDelegatorsNode(nscgenerator.NewEventDelegatorsNode(table.Events))
+
+	javaImplementor.Output += "private void handleEvent(Event event) {\n"
+	javaImplementor.Output += "int next = NEXT_STATES[state.ordinal()][event.ordinal()];\n"
+	javaImplementor.Output += fmt.Sprintf("if (next == %d) {\n", ttgenerator.Unhandled)
+	javaImplementor.Output += "unhandledTransition(state.name(), event.name());\n"
+	javaImplementor.Output += "return;\n"
+	javaImplementor.Output += "}\n"
+	javaImplementor.Output += "int[] actionList = ACTION_LISTS[ACTION_LIST_INDICES[state.ordinal()][event.ordinal()]];\n"
+	javaImplementor.Output += "setState(State.values()[next]);\n"
+	javaImplementor.Output += "for (int action : actionList) {\n"
+	javaImplementor.Output += "executeAction(action);\n"
+	javaImplementor.Output += "}\n"
+	javaImplementor.Output += "}\n"
+
+	javaImplementor.Output += "private void executeAction(int action) {\n"
+	javaImplementor.Output += "switch(action) {\n"
+	for ordinal, action := range table.Actions {
+		javaImplementor.Output += fmt.Sprintf("case %d:\n%s();\nbreak;\n", ordinal, action)
+	}
+	javaImplementor.Output += "}\n"
+	javaImplementor.Output += "}\n"
+
+	if table.ActionsName == "" {
+		for _, action := range table.Actions {
+			javaImplementor.Output += fmt.Sprintf("protected abstract void %s();\n", action)
+		}
+	}
+	javaImplementor.Output += "}\n"
+}
@@ -0,0 +1,122 @@
+package implementors
+
+import (
+	"fmt"
+
+	nscgenerator "github.com/larkvincer/dsl-fsm/generator/nestedswitchcasegenerator"
+)
+
+type KotlinNestedSwitchCaseImplementor struct {
+	Output        string
+	flags         map[string]string
+	kotlinPackage string
+}
+
+func NewKotlinNestedSwitchCaseImplementor(flags map[string]string) *KotlinNestedSwitchCaseImplementor {
+	obj := &KotlinNestedSwitchCaseImplementor{
+		flags: flags,
+	}
+	if _, ok := flags["package"]; ok {
+		obj.kotlinPackage = flags["package"]
+	}
+	return obj
+}
+
+func (kotlinImplementor *KotlinNestedSwitchCaseImplementor) GetPackage() string {
+	return kotlinImplementor.kotlinPackage
+}
+
+func (kotlinImplementor *KotlinNestedSwitchCaseImplementor) VisitSwitchCaseNode(
+	switchCaseNode *nscgenerator.SwitchCaseNode,
+) {
+	kotlinImplementor.Output += fmt.Sprintf("when (%s) {\n", switchCaseNode.VariableName)
+	switchCaseNode.GenerateCases(kotlinImplementor)
+	kotlinImplementor.Output += "}\n"
+}
+
+func (kotlinImplementor *KotlinNestedSwitchCaseImplementor) VisitCaseNode(caseNode *nscgenerator.CaseNode) {
+	kotlinImplementor.Output += fmt.Sprintf("%s.%s -> {\n", caseNode.SwitchName, caseNode.CaseName)
+	caseNode.CaseActionNode.Accept(kotlinImplementor)
+	kotlinImplementor.Output += "}\n"
+}
+
+func (kotlinImplementor *KotlinNestedSwitchCaseImplementor) VisitFunctionalCallNode(
+	functionCallNode *nscgenerator.FunctionCallNode,
+) {
+	kotlinImplementor.Output += fmt.Sprintf("%s(", functionCallNode.FunctionName)
+	if functionCallNode.Argument != nil {
+		functionCallNode.Argument.Accept(kotlinImplementor)
+	}
+	kotlinImplementor.Output += ")\n"
+}
+
+func (kotlinImplementor *KotlinNestedSwitchCaseImplementor) VisitEnumNode(enumNode *nscgenerator.EnumNode) {
+	kotlinImplementor.Output += fmt.Sprintf("enum class %s {\n", enumNode.Name)
+	for _, enumerator := range enumNode.Enumerators {
+		kotlinImplementor.Output += enumerator + ",\n"
+	}
+	kotlinImplementor.Output += "}\n\n"
+}
+
+func (kotlinImplementor *KotlinNestedSwitchCaseImplementor) VisitStatePropertyNode(
+	statePropertyNode *nscgenerator.StatePropertyNode,
+) {
+	kotlinImplementor.Output += fmt.Sprintf("private var state: State = State.%s\n\n", statePropertyNode.InitialState)
+	kotlinImplementor.Output += "val currentState: State get() = state\n\n"
+	kotlinImplementor.Output += "private fun setState(s: State) {\nstate = s\n}\n\n"
+}
+
+func (kotlinImplementor *KotlinNestedSwitchCaseImplementor) VisitEventDelegatorsNode(
+	eventDelegatorsNode *nscgenerator.EventDelegatorsNode,
+) {
+	for _, event := range eventDelegatorsNode.Events {
+		kotlinImplementor.Output += fmt.Sprintf("fun %s() = handleEvent(Event.%s)\n\n", event, event)
+	}
+}
+
+func (kotlinImplementor *KotlinNestedSwitchCaseImplementor) VisitFSMClassNode(fsmClassNode *nscgenerator.FSMClassNode) {
+	kotlinImplementor.Output += "// Generated by smc. Do not edit.\n"
+	if kotlinImplementor.kotlinPackage != "" {
+		kotlinImplementor.Output += fmt.Sprintf("package %s\n", kotlinImplementor.kotlinPackage)
+	}
+	kotlinImplementor.Output += "\n"
+
+	actionsName := fsmClassNode.ActionsName
+	if actionsName == "" {
+		actionsName = fsmClassNode.ClassName + "Actions"
+	}
+	kotlinImplementor.Output += fmt.Sprintf("interface %s {\n", actionsName)
+	for _, action := range fsmClassNode.Actions {
+		kotlinImplementor.Output += fmt.Sprintf("fun %s()\n", action)
+	}
+	kotlinImplementor.Output += "}\n\n"
+
+	kotlinImplementor.Output += fmt.Sprintf("abstract class %s : %s {\n", fsmClassNode.ClassName, actionsName)
+	fsmClassNode.StateEnum.Accept(kotlinImplementor)
+	fsmClassNode.EventEnum.Accept(kotlinImplementor)
+	fsmClassNode.StateProperty.Accept(kotlinImplementor)
+	fsmClassNode.Delegators.Accept(kotlinImplementor)
+	fsmClassNode.HandleEvent.Accept(kotlinImplementor)
+	kotlinImplementor.Output += "\nabstract fun unhandledTransition(state: String, event: String)\n"
+	kotlinImplementor.Output += "}\n"
+}
+
+func (kotlinImplementor *KotlinNestedSwitchCaseImplementor) VisitHandleEventNode(
+	handleEventNode *nscgenerator.HandleEventNode,
+) {
+	kotlinImplementor.Output += "private fun handleEvent(event: Event) {\n"
+	handleEventNode.SwitchCase.Accept(kotlinImplementor)
+	kotlinImplementor.Output += "}\n"
+}
+
+func (kotlinImplementor *KotlinNestedSwitchCaseImplementor) VisitEnumeratorNode(
+	enumeratorNode *nscgenerator.EnumeratorNode,
+) {
+	kotlinImplementor.Output += fmt.Sprintf("%s.%s", enumeratorNode.Enumeration, enumeratorNode.Enumerator)
+}
+
+func (kotlinImplementor *KotlinNestedSwitchCaseImplementor) VisitDefaultCaseNode(
+	defaultCaseNode *nscgenerator.DefaultCaseNode,
+) {
+	kotlinImplementor.Output += "else -> unhandledTransition(state.name, event.name)\n"
+}
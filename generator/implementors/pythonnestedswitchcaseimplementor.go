@@ -0,0 +1,209 @@
+package implementors
+
+import (
+	"fmt"
+	"strings"
+
+	nscgenerator "github.com/larkvincer/dsl-fsm/generator/nestedswitchcasegenerator"
+)
+
+// PythonNestedSwitchCaseImplementor turns the switch/case tree into if/elif
+// chains, so the output does not need the match statement of Python 3.10.
+// Python is indentation sensitive, so unlike the brace languages the
+// implementor tracks the indentation itself.
+type PythonNestedSwitchCaseImplementor struct {
+	Output       string
+	flags        map[string]string
+	indentation  int
+	switchScopes []*pythonSwitchScope
+}
+
+type pythonSwitchScope struct {
+	variableName string
+	hasCases     bool
+}
+
+func NewPythonNestedSwitchCaseImplementor(flags map[string]string) *PythonNestedSwitchCaseImplementor {
+	return &PythonNestedSwitchCaseImplementor{
+		flags: flags,
+	}
+}
+
+func (pythonImplementor *PythonNestedSwitchCaseImplementor) VisitSwitchCaseNode(
+	switchCaseNode *nscgenerator.SwitchCaseNode,
+) {
+	variableName := switchCaseNode.VariableName
+	if variableName == "state" {
+		variableName = "self._state"
+	}
+	pythonImplementor.switchScopes = append(pythonImplementor.switchScopes, &pythonSwitchScope{variableName: variableName})
+	switchCaseNode.GenerateCases(pythonImplementor)
+	pythonImplementor.switchScopes = pythonImplementor.switchScopes[:len(pythonImplementor.switchScopes)-1]
+}
+
+func (pythonImplementor *PythonNestedSwitchCaseImplementor) VisitCaseNode(caseNode *nscgenerator.CaseNode) {
+	scope := pythonImplementor.currentSwitch()
+	keyword := "elif"
+	if !scope.hasCases {
+		keyword = "if"
+		scope.hasCases = true
+	}
+	pythonImplementor.line(fmt.Sprintf(
+		"%s %s == %s.%s:", keyword, scope.variableName, caseNode.SwitchName, caseNode.CaseName,
+	))
+	pythonImplementor.indentation++
+	caseNode.CaseActionNode.Accept(pythonImplementor)
+	pythonImplementor.indentation--
+}
+
+func (pythonImplementor *PythonNestedSwitchCaseImplementor) VisitFunctionalCallNode(
+	functionCallNode *nscgenerator.FunctionCallNode,
+) {
+	if functionCallNode.Argument != nil {
+		pythonImplementor.Output += pythonImplementor.indent() + fmt.Sprintf("self._%s(", functionCallNode.FunctionName)
+		functionCallNode.Argument.Accept(pythonImplementor)
+		pythonImplementor.Output += ")\n"
+	} else {
+		pythonImplementor.line(fmt.Sprintf("self.%s()", functionCallNode.FunctionName))
+	}
+}
+
+func (pythonImplementor *PythonNestedSwitchCaseImplementor) VisitEnumNode(enumNode *nscgenerator.EnumNode) {
+	pythonImplementor.line(fmt.Sprintf("class %s(enum.Enum):", enumNode.Name))
+	pythonImplementor.indentation++
+	for _, enumerator := range enumNode.Enumerators {
+		pythonImplementor.line(fmt.Sprintf("%s = %q", enumerator, enumerator))
+	}
+	if len(enumNode.Enumerators) == 0 {
+		pythonImplementor.line("pass")
+	}
+	pythonImplementor.indentation--
+	pythonImplementor.Output += "\n\n"
+}
+
+func (pythonImplementor *PythonNestedSwitchCaseImplementor) VisitStatePropertyNode(
+	statePropertyNode *nscgenerator.StatePropertyNode,
+) {
+	pythonImplementor.line("def __init__(self, unhandled_transition=None):")
+	pythonImplementor.indentation++
+	pythonImplementor.line(fmt.Sprintf("self._state = State.%s", statePropertyNode.InitialState))
+	pythonImplementor.line("self._unhandled_transition = unhandled_transition")
+	pythonImplementor.indentation--
+	pythonImplementor.Output += "\n"
+
+	pythonImplementor.line("@property")
+	pythonImplementor.line("def state(self):")
+	pythonImplementor.line("    return self._state")
+	pythonImplementor.Output += "\n"
+
+	pythonImplementor.line("def _setState(self, state):")
+	pythonImplementor.line("    self._state = state")
+	pythonImplementor.Output += "\n"
+
+	pythonImplementor.line("def unhandledTransition(self, state, event):")
+	pythonImplementor.indentation++
+	pythonImplementor.line(`"""Called for events the current state does not handle.`)
+	pythonImplementor.Output += "\n"
+	pythonImplementor.line("Delegates to the unhandled_transition callable given to the")
+	pythonImplementor.line("constructor, if any. Subclasses may override it instead.")
+	pythonImplementor.line(`"""`)
+	pythonImplementor.line("if self._unhandled_transition is not None:")
+	pythonImplementor.line("    self._unhandled_transition(state, event)")
+	pythonImplementor.indentation--
+	pythonImplementor.Output += "\n"
+}
+
+func (pythonImplementor *PythonNestedSwitchCaseImplementor) VisitEventDelegatorsNode(
+	eventDelegatorsNode *nscgenerator.EventDelegatorsNode,
+) {
+	for _, event := range eventDelegatorsNode.Events {
+		pythonImplementor.line(fmt.Sprintf("def %s(self):", event))
+		pythonImplementor.line(fmt.Sprintf("    self._handleEvent(Event.%s)", event))
+		pythonImplementor.Output += "\n"
+	}
+}
+
+func (pythonImplementor *PythonNestedSwitchCaseImplementor) VisitFSMClassNode(fsmClassNode *nscgenerator.FSMClassNode) {
+	pythonImplementor.Output += "# Generated by smc. Do not edit.\n"
+	pythonImplementor.Output += "import abc\n"
+	pythonImplementor.Output += "import enum\n\n\n"
+
+	fsmClassNode.StateEnum.Accept(pythonImplementor)
+	fsmClassNode.EventEnum.Accept(pythonImplementor)
+
+	baseClass := "abc.ABC"
+	if fsmClassNode.ActionsName != "" {
+		pythonImplementor.line(fmt.Sprintf("class %s(abc.ABC):", fsmClassNode.ActionsName))
+		pythonImplementor.indentation++
+		pythonImplementor.abstractActions(fsmClassNode.Actions)
+		pythonImplementor.indentation--
+		pythonImplementor.Output += "\n\n"
+		baseClass = fsmClassNode.ActionsName
+	}
+
+	pythonImplementor.line(fmt.Sprintf("class %s(%s):", fsmClassNode.ClassName, baseClass))
+	pythonImplementor.indentation++
+	fsmClassNode.StateProperty.Accept(pythonImplementor)
+	fsmClassNode.Delegators.Accept(pythonImplementor)
+	fsmClassNode.HandleEvent.Accept(pythonImplementor)
+	if fsmClassNode.ActionsName == "" && len(fsmClassNode.Actions) > 0 {
+		pythonImplementor.Output += "\n"
+		pythonImplementor.abstractActions(fsmClassNode.Actions)
+	}
+	pythonImplementor.indentation--
+}
+
+func (pythonImplementor *PythonNestedSwitchCaseImplementor) VisitHandleEventNode(
+	handleEventNode *nscgenerator.HandleEventNode,
+) {
+	pythonImplementor.line("def _handleEvent(self, event):")
+	pythonImplementor.indentation++
+	handleEventNode.SwitchCase.Accept(pythonImplementor)
+	if len(handleEventNode.SwitchCase.CaseNodes) == 0 {
+		pythonImplementor.line("pass")
+	}
+	pythonImplementor.indentation--
+}
+
+func (pythonImplementor *PythonNestedSwitchCaseImplementor) VisitEnumeratorNode(
+	enumeratorNode *nscgenerator.EnumeratorNode,
+) {
+	pythonImplementor.Output += fmt.Sprintf("%s.%s", enumeratorNode.Enumeration, enumeratorNode.Enumerator)
+}
+
+func (pythonImplementor *PythonNestedSwitchCaseImplementor) VisitDefaultCaseNode(
+	defaultCaseNode *nscgenerator.DefaultCaseNode,
+) {
+	if pythonImplementor.currentSwitch().hasCases {
+		pythonImplementor.line("else:")
+		pythonImplementor.indentation++
+		defer func() { pythonImplementor.indentation-- }()
+	}
+	pythonImplementor.line("self.unhandledTransition(self._state.name, event.name)")
+}
+
+func (pythonImplementor *PythonNestedSwitchCaseImplementor) abstractActions(actions []string) {
+	for i, action := range actions {
+		if i > 0 {
+			pythonImplementor.Output += "\n"
+		}
+		pythonImplementor.line("@abc.abstractmethod")
+		pythonImplementor.line(fmt.Sprintf("def %s(self):", action))
+		pythonImplementor.line("    pass")
+	}
+	if len(actions) == 0 {
+		pythonImplementor.line("pass")
+	}
+}
+
+func (pythonImplementor *PythonNestedSwitchCaseImplementor) currentSwitch() *pythonSwitchScope {
+	return pythonImplementor.switchScopes[len(pythonImplementor.switchScopes)-1]
+}
+
+func (pythonImplementor *PythonNestedSwitchCaseImplementor) line(text string) {
+	pythonImplementor.Output += pythonImplementor.indent() + text + "\n"
+}
+
+func (pythonImplementor *PythonNestedSwitchCaseImplementor) indent() string {
+	return strings.Repeat("    ", pythonImplementor.indentation)
+}
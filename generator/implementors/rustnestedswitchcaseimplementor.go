@@ -0,0 +1,140 @@
+package implementors
+
+import (
+	"fmt"
+
+	nscgenerator "github.com/larkvincer/dsl-fsm/generator/nestedswitchcasegenerator"
+)
+
+// RustNestedSwitchCaseImplementor names enum variants in PascalCase and
+// methods in snake_case, as rustc expects. The machine owns its actions,
+// which implement the trait named by the Actions header.
+type RustNestedSwitchCaseImplementor struct {
+	Output      string
+	flags       map[string]string
+	className   string
+	actionsName string
+}
+
+func NewRustNestedSwitchCaseImplementor(flags map[string]string) *RustNestedSwitchCaseImplementor {
+	return &RustNestedSwitchCaseImplementor{
+		flags: flags,
+	}
+}
+
+func (rustImplementor *RustNestedSwitchCaseImplementor) VisitSwitchCaseNode(
+	switchCaseNode *nscgenerator.SwitchCaseNode,
+) {
+	variableName := switchCaseNode.VariableName
+	if variableName == "state" {
+		variableName = "self.state"
+	}
+	rustImplementor.Output += fmt.Sprintf("match %s {\n", variableName)
+	switchCaseNode.GenerateCases(rustImplementor)
+	rustImplementor.Output += "}\n"
+}
+
+func (rustImplementor *RustNestedSwitchCaseImplementor) VisitCaseNode(caseNode *nscgenerator.CaseNode) {
+	rustImplementor.Output += fmt.Sprintf("%s::%s => {\n", caseNode.SwitchName, Capitalize(caseNode.CaseName))
+	caseNode.CaseActionNode.Accept(rustImplementor)
+	rustImplementor.Output += "}\n"
+}
+
+func (rustImplementor *RustNestedSwitchCaseImplementor) VisitFunctionalCallNode(
+	functionCallNode *nscgenerator.FunctionCallNode,
+) {
+	if functionCallNode.Argument != nil {
+		rustImplementor.Output += fmt.Sprintf("self.%s(", SnakeCase(functionCallNode.FunctionName))
+		functionCallNode.Argument.Accept(rustImplementor)
+	} else {
+		rustImplementor.Output += fmt.Sprintf("self.actions.%s(", SnakeCase(functionCallNode.FunctionName))
+	}
+	rustImplementor.Output += ");\n"
+}
+
+func (rustImplementor *RustNestedSwitchCaseImplementor) VisitEnumNode(enumNode *nscgenerator.EnumNode) {
+	rustImplementor.Output += "#[derive(Debug, Clone, Copy, PartialEq, Eq)]\n"
+	rustImplementor.Output += fmt.Sprintf("pub enum %s {\n", enumNode.Name)
+	for _, enumerator := range enumNode.Enumerators {
+		rustImplementor.Output += fmt.Sprintf("%s,\n", Capitalize(enumerator))
+	}
+	rustImplementor.Output += "}\n\n"
+}
+
+func (rustImplementor *RustNestedSwitchCaseImplementor) VisitStatePropertyNode(
+	statePropertyNode *nscgenerator.StatePropertyNode,
+) {
+	rustImplementor.Output += "pub fn new(actions: A) -> Self {\n"
+	rustImplementor.Output += fmt.Sprintf(
+		"%s {\nstate: State::%s,\nactions,\n}\n", rustImplementor.className, Capitalize(statePropertyNode.InitialState),
+	)
+	rustImplementor.Output += "}\n\n"
+	rustImplementor.Output += "pub fn state(&self) -> State {\nself.state\n}\n\n"
+	rustImplementor.Output += "pub fn actions(&self) -> &A {\n&self.actions\n}\n\n"
+	rustImplementor.Output += "pub fn actions_mut(&mut self) -> &mut A {\n&mut self.actions\n}\n\n"
+	rustImplementor.Output += "fn set_state(&mut self, state: State) {\nself.state = state;\n}\n\n"
+}
+
+func (rustImplementor *RustNestedSwitchCaseImplementor) VisitEventDelegatorsNode(
+	eventDelegatorsNode *nscgenerator.EventDelegatorsNode,
+) {
+	for _, event := range eventDelegatorsNode.Events {
+		rustImplementor.Output += fmt.Sprintf(
+			"pub fn %s(&mut self) {\nself.handle_event(Event::%s);\n}\n\n", SnakeCase(event), Capitalize(event),
+		)
+	}
+}
+
+func (rustImplementor *RustNestedSwitchCaseImplementor) VisitFSMClassNode(fsmClassNode *nscgenerator.FSMClassNode) {
+	rustImplementor.className = fsmClassNode.ClassName
+	rustImplementor.actionsName = fsmClassNode.ActionsName
+	if rustImplementor.actionsName == "" {
+		rustImplementor.actionsName = fsmClassNode.ClassName + "Actions"
+	}
+
+	rustImplementor.Output += "// Generated by smc. Do not edit.\n\n"
+	fsmClassNode.StateEnum.Accept(rustImplementor)
+	fsmClassNode.EventEnum.Accept(rustImplementor)
+
+	rustImplementor.Output += fmt.Sprintf("pub trait %s {\n", rustImplementor.actionsName)
+	for _, action := range fsmClassNode.Actions {
+		rustImplementor.Output += fmt.Sprintf("fn %s(&mut self);\n", SnakeCase(action))
+	}
+	rustImplementor.Output += "\n/// Called for events the current state does not handle.\n"
+	rustImplementor.Output += "fn unhandled_transition(&mut self, _state: State, _event: Event) {}\n"
+	rustImplementor.Output += "}\n\n"
+
+	rustImplementor.Output += fmt.Sprintf("pub struct %s<A: %s> {\n", rustImplementor.className, rustImplementor.actionsName)
+	rustImplementor.Output += "state: State,\n"
+	rustImplementor.Output += "actions: A,\n"
+	rustImplementor.Output += "}\n\n"
+
+	rustImplementor.Output += fmt.Sprintf(
+		"impl<A: %s> %s<A> {\n", rustImplementor.actionsName, rustImplementor.className,
+	)
+	fsmClassNode.StateProperty.Accept(rustImplementor)
+	fsmClassNode.Delegators.Accept(rustImplementor)
+	fsmClassNode.HandleEvent.Accept(rustImplementor)
+	rustImplementor.Output += "}\n"
+}
+
+func (rustImplementor *RustNestedSwitchCaseImplementor) VisitHandleEventNode(
+	handleEventNode *nscgenerator.HandleEventNode,
+) {
+	rustImplementor.Output += "#[allow(unreachable_patterns)]\n"
+	rustImplementor.Output += "fn handle_event(&mut self, event: Event) {\n"
+	handleEventNode.SwitchCase.Accept(rustImplementor)
+	rustImplementor.Output += "}\n"
+}
+
+func (rustImplementor *RustNestedSwitchCaseImplementor) VisitEnumeratorNode(
+	enumeratorNode *nscgenerator.EnumeratorNode,
+) {
+	rustImplementor.Output += fmt.Sprintf("%s::%s", enumeratorNode.Enumeration, Capitalize(enumeratorNode.Enumerator))
+}
+
+func (rustImplementor *RustNestedSwitchCaseImplementor) VisitDefaultCaseNode(
+	defaultCaseNode *nscgenerator.DefaultCaseNode,
+) {
+	rustImplementor.Output += "_ => self.actions.unhandled_transition(self.state, event),\n"
+}
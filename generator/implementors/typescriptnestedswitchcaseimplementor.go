@@ -0,0 +1,140 @@
+package implementors
+
+import (
+	"fmt"
+	"strings"
+
+	nscgenerator "github.com/larkvincer/dsl-fsm/generator/nestedswitchcasegenerator"
+)
+
+// TypeScriptNestedSwitchCaseImplementor emits an ES module by default. With
+// the module=commonjs flag the declarations are wrapped in a namespace that
+// is exported with `export =`, which tsc compiles to module.exports.
+type TypeScriptNestedSwitchCaseImplementor struct {
+	Output   string
+	flags    map[string]string
+	commonJS bool
+}
+
+func NewTypeScriptNestedSwitchCaseImplementor(flags map[string]string) *TypeScriptNestedSwitchCaseImplementor {
+	obj := &TypeScriptNestedSwitchCaseImplementor{
+		flags: flags,
+	}
+	if module, ok := flags["module"]; ok && strings.EqualFold(module, "commonjs") {
+		obj.commonJS = true
+	}
+	return obj
+}
+
+func (tsImplementor *TypeScriptNestedSwitchCaseImplementor) VisitSwitchCaseNode(
+	switchCaseNode *nscgenerator.SwitchCaseNode,
+) {
+	variableName := switchCaseNode.VariableName
+	if variableName == "state" {
+		variableName = "this.state"
+	}
+	tsImplementor.Output += fmt.Sprintf("switch (%s) {\n", variableName)
+	switchCaseNode.GenerateCases(tsImplementor)
+	tsImplementor.Output += "}\n"
+}
+
+func (tsImplementor *TypeScriptNestedSwitchCaseImplementor) VisitCaseNode(caseNode *nscgenerator.CaseNode) {
+	tsImplementor.Output += fmt.Sprintf("case %s.%s:\n", caseNode.SwitchName, caseNode.CaseName)
+	caseNode.CaseActionNode.Accept(tsImplementor)
+	tsImplementor.Output += "break;\n"
+}
+
+func (tsImplementor *TypeScriptNestedSwitchCaseImplementor) VisitFunctionalCallNode(
+	functionCallNode *nscgenerator.FunctionCallNode,
+) {
+	tsImplementor.Output += fmt.Sprintf("this.%s(", functionCallNode.FunctionName)
+	if functionCallNode.Argument != nil {
+		functionCallNode.Argument.Accept(tsImplementor)
+	}
+	tsImplementor.Output += ");\n"
+}
+
+func (tsImplementor *TypeScriptNestedSwitchCaseImplementor) VisitEnumNode(enumNode *nscgenerator.EnumNode) {
+	tsImplementor.Output += fmt.Sprintf("export enum %s {\n", enumNode.Name)
+	for _, enumerator := range enumNode.Enumerators {
+		tsImplementor.Output += fmt.Sprintf("%s = \"%s\",\n", enumerator, enumerator)
+	}
+	tsImplementor.Output += "}\n\n"
+}
+
+func (tsImplementor *TypeScriptNestedSwitchCaseImplementor) VisitStatePropertyNode(
+	statePropertyNode *nscgenerator.StatePropertyNode,
+) {
+	tsImplementor.Output += fmt.Sprintf("private state: State = State.%s;\n\n", statePropertyNode.InitialState)
+	tsImplementor.Output += "getState(): State {\nreturn this.state;\n}\n\n"
+	tsImplementor.Output += "private setState(state: State): void {\nthis.state = state;\n}\n\n"
+}
+
+func (tsImplementor *TypeScriptNestedSwitchCaseImplementor) VisitEventDelegatorsNode(
+	eventDelegatorsNode *nscgenerator.EventDelegatorsNode,
+) {
+	for _, event := range eventDelegatorsNode.Events {
+		tsImplementor.Output += fmt.Sprintf("%s(): void {\nthis.handleEvent(Event.%s);\n}\n\n", event, event)
+	}
+}
+
+func (tsImplementor *TypeScriptNestedSwitchCaseImplementor) VisitFSMClassNode(fsmClassNode *nscgenerator.FSMClassNode) {
+	tsImplementor.Output += "// Generated by smc. Do not edit.\n\n"
+	namespace := fsmClassNode.ClassName + "Module"
+	if tsImplementor.commonJS {
+		tsImplementor.Output += fmt.Sprintf("namespace %s {\n", namespace)
+	}
+
+	fsmClassNode.StateEnum.Accept(tsImplementor)
+	fsmClassNode.EventEnum.Accept(tsImplementor)
+
+	actionModifier := "protected abstract"
+	if fsmClassNode.ActionsName != "" {
+		tsImplementor.Output += fmt.Sprintf("export interface %s {\n", fsmClassNode.ActionsName)
+		for _, action := range fsmClassNode.Actions {
+			tsImplementor.Output += fmt.Sprintf("%s(): void;\n", action)
+		}
+		tsImplementor.Output += "}\n\n"
+		tsImplementor.Output += fmt.Sprintf(
+			"export abstract class %s implements %s {\n",
+			fsmClassNode.ClassName, fsmClassNode.ActionsName,
+		)
+		actionModifier = "abstract"
+	} else {
+		tsImplementor.Output += fmt.Sprintf("export abstract class %s {\n", fsmClassNode.ClassName)
+	}
+
+	fsmClassNode.StateProperty.Accept(tsImplementor)
+	fsmClassNode.Delegators.Accept(tsImplementor)
+	fsmClassNode.HandleEvent.Accept(tsImplementor)
+	tsImplementor.Output += "\nabstract unhandledTransition(state: string, event: string): void;\n"
+	for _, action := range fsmClassNode.Actions {
+		tsImplementor.Output += fmt.Sprintf("%s %s(): void;\n", actionModifier, action)
+	}
+	tsImplementor.Output += "}\n"
+
+	if tsImplementor.commonJS {
+		tsImplementor.Output += "}\n\n"
+		tsImplementor.Output += fmt.Sprintf("export = %s;\n", namespace)
+	}
+}
+
+func (tsImplementor *TypeScriptNestedSwitchCaseImplementor) VisitHandleEventNode(
+	handleEventNode *nscgenerator.HandleEventNode,
+) {
+	tsImplementor.Output += "private handleEvent(event: Event): void {\n"
+	handleEventNode.SwitchCase.Accept(tsImplementor)
+	tsImplementor.Output += "}\n"
+}
+
+func (tsImplementor *TypeScriptNestedSwitchCaseImplementor) VisitEnumeratorNode(
+	enumeratorNode *nscgenerator.EnumeratorNode,
+) {
+	tsImplementor.Output += fmt.Sprintf("%s.%s", enumeratorNode.Enumeration, enumeratorNode.Enumerator)
+}
+
+func (tsImplementor *TypeScriptNestedSwitchCaseImplementor) VisitDefaultCaseNode(
+	defaultCaseNode *nscgenerator.DefaultCaseNode,
+) {
+	tsImplementor.Output += "default:\nthis.unhandledTransition(this.state, event);\nbreak;\n"
+}
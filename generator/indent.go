@@ -0,0 +1,50 @@
+package generator
+
+import "strings"
+
+// indentBlocks indents brace-delimited source the implementors write flat.
+// Statements after a case or default label get one extra level until the
+// next label or the end of the switch; C++ access specifiers are outdented
+// to the level of their class.
+func indentBlocks(source, indent string) string {
+	type block struct{ inCase bool }
+	blocks := []block{{}}
+	lines := strings.Split(source, "\n")
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			lines[i] = ""
+			continue
+		}
+		opens := strings.Count(trimmed, "{")
+		closes := strings.Count(trimmed, "}")
+		if strings.HasPrefix(trimmed, "}") && len(blocks) > 1 {
+			blocks = blocks[:len(blocks)-1]
+			closes--
+		}
+		isLabel := strings.HasPrefix(trimmed, "case ") || strings.HasPrefix(trimmed, "default:")
+		isAccessSpecifier := trimmed == "public:" || trimmed == "protected:" || trimmed == "private:"
+
+		depth := len(blocks) - 1
+		for j, b := range blocks {
+			if b.inCase && !(isLabel && j == len(blocks)-1) {
+				depth++
+			}
+		}
+		if isAccessSpecifier && depth > 0 {
+			depth--
+		}
+		lines[i] = strings.Repeat(indent, depth) + trimmed
+
+		if isLabel {
+			blocks[len(blocks)-1].inCase = true
+		}
+		for ; opens > 0; opens-- {
+			blocks = append(blocks, block{})
+		}
+		for ; closes > 0 && len(blocks) > 1; closes-- {
+			blocks = blocks[:len(blocks)-1]
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -1,10 +1,9 @@
 package generator
 
 import (
-	"fmt"
-
 	"github.com/larkvincer/dsl-fsm/generator/implementors"
 	nscgenerator "github.com/larkvincer/dsl-fsm/generator/nestedswitchcasegenerator"
+	ttgenerator "github.com/larkvincer/dsl-fsm/generator/transitiontablegenerator"
 )
 
 type JavaCodeGenerator struct {
@@ -23,7 +22,47 @@ func (javaGenerator *JavaCodeGenerator) GetImplementer() nscgenerator.NSCNodeVis
 	return javaGenerator.javaNestedSwitchCaseImplementor
 }
 
-func (javaGenerator *JavaCodeGenerator) WriteFiles() {
-	fmt.Println("Generated code:")
-	fmt.Print(javaGenerator.javaNestedSwitchCaseImplementor.Output)
+func (javaGenerator *JavaCodeGenerator) GetArtifacts(fsmName string) []Artifact {
+	return []Artifact{{
+		Name:    packagePath(javaGenerator.javaNestedSwitchCaseImplementor.GetPackage(), ".") + fsmName + ".java",
+		Content: javaGenerator.javaNestedSwitchCaseImplementor.Output,
+	}}
+}
+
+func (javaGenerator *JavaCodeGenerator) MemberName(name string) string {
+	return name
+}
+
+// FixedMembers are the methods of the generated class besides the events
+// and actions. Java keeps fields and nested types apart from methods.
+func (javaGenerator *JavaCodeGenerator) FixedMembers(className string) map[string]bool {
+	return setOf("unhandledTransition", "setState", "handleEvent")
+}
+
+// JavaTableCodeGenerator shares JavaCodeGenerator's artifacts, as the table
+// implementor writes into its embedded nested switch/case implementor.
+type JavaTableCodeGenerator struct {
+	JavaCodeGenerator
+	javaTransitionTableImplementor *implementors.JavaTransitionTableImplementor
+}
+
+func NewJavaTableCodeGenerator(
+	javaTransitionTableImplementor *implementors.JavaTransitionTableImplementor,
+) *JavaTableCodeGenerator {
+	return &JavaTableCodeGenerator{
+		JavaCodeGenerator:              *NewJavaCodeGenerator(&javaTransitionTableImplementor.JavaNestedSwitchCaseImplementor),
+		javaTransitionTableImplementor: javaTransitionTableImplementor,
+	}
+}
+
+func (javaGenerator *JavaTableCodeGenerator) GetTableImplementer() ttgenerator.TransitionTableImplementor {
+	return javaGenerator.javaTransitionTableImplementor
+}
+
+// FixedMembers adds executeAction, which runs the actions of the table, to
+// the methods of JavaCodeGenerator.
+func (javaGenerator *JavaTableCodeGenerator) FixedMembers(className string) map[string]bool {
+	fixedMembers := javaGenerator.JavaCodeGenerator.FixedMembers(className)
+	fixedMembers["executeAction"] = true
+	return fixedMembers
 }
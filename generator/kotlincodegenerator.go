@@ -0,0 +1,39 @@
+package generator
+
+import (
+	"github.com/larkvincer/dsl-fsm/generator/implementors"
+	nscgenerator "github.com/larkvincer/dsl-fsm/generator/nestedswitchcasegenerator"
+)
+
+type KotlinCodeGenerator struct {
+	kotlinNestedSwitchCaseImplementor *implementors.KotlinNestedSwitchCaseImplementor
+}
+
+func NewKotlinCodeGenerator(
+	kotlinNestedSwitchCaseImplementor *implementors.KotlinNestedSwitchCaseImplementor,
+) *KotlinCodeGenerator {
+	return &KotlinCodeGenerator{
+		kotlinNestedSwitchCaseImplementor: kotlinNestedSwitchCaseImplementor,
+	}
+}
+
+func (kotlinGenerator *KotlinCodeGenerator) GetImplementer() nscgenerator.NSCNodeVisitor {
+	return kotlinGenerator.kotlinNestedSwitchCaseImplementor
+}
+
+func (kotlinGenerator *KotlinCodeGenerator) GetArtifacts(fsmName string) []Artifact {
+	return []Artifact{{
+		Name:    packagePath(kotlinGenerator.kotlinNestedSwitchCaseImplementor.GetPackage(), ".") + fsmName + ".kt",
+		Content: indentBlocks(kotlinGenerator.kotlinNestedSwitchCaseImplementor.Output, "    "),
+	}}
+}
+
+func (kotlinGenerator *KotlinCodeGenerator) MemberName(name string) string {
+	return name
+}
+
+// FixedMembers are the members of the generated class besides the events
+// and the actions, which it inherits from the actions interface.
+func (kotlinGenerator *KotlinCodeGenerator) FixedMembers(className string) map[string]bool {
+	return setOf("state", "currentState", "setState", "handleEvent", "unhandledTransition")
+}
@@ -0,0 +1,59 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestKotlinArtifact(t *testing.T) {
+	artifacts := generate(t, twoCoinTurnstile, "kotlin", map[string]string{"package": "com.firsttry"})
+	if len(artifacts) != 1 || artifacts[0].Name != "com/firsttry/TwoCoinTurnstile.kt" {
+		t.Fatalf("unexpected artifacts %v", artifacts)
+	}
+	content := artifacts[0].Content
+	for _, expected := range []string{
+		"// Generated by smc. Do not edit.\npackage com.firsttry\n",
+		"interface Turnstile {\n    fun alarmOff()\n",
+		"abstract class TwoCoinTurnstile : Turnstile {\n    enum class State {\n        Alarming,\n",
+		"    fun Coin() = handleEvent(Event.Coin)\n",
+		"        when (state) {\n            State.Alarming -> {\n                when (event) {\n",
+		"                    else -> unhandledTransition(state.name, event.name)\n",
+	} {
+		if !strings.Contains(content, expected) {
+			t.Errorf("expected generated code to contain %q:\n%s", expected, content)
+		}
+	}
+}
+
+func TestKotlinActionsInterfaceDefaultsToFsmName(t *testing.T) {
+	artifacts := generate(t, "FSM: f\nInitial: i\n{\ni e i a\n}\n", "kotlin", map[string]string{})
+	if artifacts[0].Name != "f.kt" ||
+		!strings.Contains(artifacts[0].Content, "interface fActions {\n    fun a()\n}\n\nabstract class f : fActions {\n") {
+		t.Fatalf("expected an fActions interface:\n%s", artifacts[0].Content)
+	}
+}
+
+func TestKotlinRejectsCollidingMembers(t *testing.T) {
+	for name, test := range map[string]struct{ source, message string }{
+		"event and action": {"FSM: f\nInitial: a\n{\n  a lock a lock\n}\n",
+			"event lock and action lock of f both become the method lock"},
+		"event and fixed member": {"FSM: f\nInitial: a\n{\n  a currentState a {}\n}\n",
+			"event currentState of f collides with the currentState member of the generated class"},
+		"action and fixed member": {"FSM: f\nInitial: a\n{\n  a e a unhandledTransition\n}\n",
+			"action unhandledTransition of f collides with the unhandledTransition member of the generated class"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := generateError(t, test.source, "kotlin", map[string]string{})
+			if err == nil || err.Error() != test.message {
+				t.Fatalf("expected %q, got %v", test.message, err)
+			}
+		})
+	}
+}
+
+func TestKotlinArtifactCompiles(t *testing.T) {
+	kotlinc := lookPath(t, "kotlinc")
+	directory := t.TempDir()
+	writeArtifacts(t, directory, generate(t, twoCoinTurnstile, "kotlin", map[string]string{"package": "com.firsttry"}))
+	run(t, directory, kotlinc, "com/firsttry/TwoCoinTurnstile.kt", "-d", "out")
+}
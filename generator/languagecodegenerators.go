@@ -0,0 +1,183 @@
+package generator
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/larkvincer/dsl-fsm/generator/implementors"
+	"github.com/larkvincer/dsl-fsm/optimizer"
+	"github.com/larkvincer/dsl-fsm/semanticanalyzer"
+)
+
+// Generator turns a state machine into artifacts. Languages that fit the
+// nested switch/case tree register a LanguageCodeGenerator and run through
+// CodeGenerator; the others implement Generator directly.
+type Generator interface {
+	Generate(machine *StateMachine) ([]Artifact, error)
+}
+
+// StateMachine holds both forms of an analyzed state machine. Most
+// generators read the optimized one; generators that keep the superstate
+// hierarchy, which optimization flattens away, read the semantic one.
+type StateMachine struct {
+	Semantic  *semanticanalyzer.SemanticStateMachine
+	Optimized *optimizer.OptimizedStateMachine
+}
+
+type GeneratorFactory func(flags map[string]string) (Generator, error)
+
+type LanguageCodeGeneratorFactory func(flags map[string]string) LanguageCodeGenerator
+
+var languageCodeGenerators = map[string]LanguageCodeGeneratorFactory{
+	"java": func(flags map[string]string) LanguageCodeGenerator {
+		return NewJavaCodeGenerator(implementors.NewJavaNestedSwitchCaseImplementor(flags))
+	},
+	"c": func(flags map[string]string) LanguageCodeGenerator {
+		return NewCCodeGenerator(implementors.NewCNestedSwitchCaseImplementor(flags))
+	},
+	"cpp": func(flags map[string]string) LanguageCodeGenerator {
+		return NewCppCodeGenerator(implementors.NewCppNestedSwitchCaseImplementor(flags))
+	},
+	"csharp": func(flags map[string]string) LanguageCodeGenerator {
+		return NewCSharpCodeGenerator(implementors.NewCSharpNestedSwitchCaseImplementor(flags))
+	},
+	"go": func(flags map[string]string) LanguageCodeGenerator {
+		return NewGoCodeGenerator(implementors.NewGoNestedSwitchCaseImplementor(flags))
+	},
+	"kotlin": func(flags map[string]string) LanguageCodeGenerator {
+		return NewKotlinCodeGenerator(implementors.NewKotlinNestedSwitchCaseImplementor(flags))
+	},
+	"python": func(flags map[string]string) LanguageCodeGenerator {
+		return NewPythonCodeGenerator(implementors.NewPythonNestedSwitchCaseImplementor(flags))
+	},
+	"rust": func(flags map[string]string) LanguageCodeGenerator {
+		return NewRustCodeGenerator(implementors.NewRustNestedSwitchCaseImplementor(flags))
+	},
+	"typescript": func(flags map[string]string) LanguageCodeGenerator {
+		return NewTypeScriptCodeGenerator(implementors.NewTypeScriptNestedSwitchCaseImplementor(flags))
+	},
+}
+
+type TableLanguageCodeGeneratorFactory func(flags map[string]string) TableLanguageCodeGenerator
+
+// tableCodeGenerators are the languages that also support strategy=table.
+var tableCodeGenerators = map[string]TableLanguageCodeGeneratorFactory{
+	"go": func(flags map[string]string) TableLanguageCodeGenerator {
+		return NewGoTableCodeGenerator(implementors.NewGoTransitionTableImplementor(flags))
+	},
+	"java": func(flags map[string]string) TableLanguageCodeGenerator {
+		return NewJavaTableCodeGenerator(implementors.NewJavaTransitionTableImplementor(flags))
+	},
+}
+
+// statePatternGenerators are the languages that also support strategy=state.
+var statePatternGenerators = map[string]GeneratorFactory{
+	"java": func(flags map[string]string) (Generator, error) {
+		return NewStatePatternGenerator(flags), nil
+	},
+}
+
+var generators = map[string]GeneratorFactory{
+	"dot": func(flags map[string]string) (Generator, error) {
+		return NewDotGenerator(flags)
+	},
+	"elixir": func(flags map[string]string) (Generator, error) {
+		return NewGenStatemGenerator(Elixir, flags), nil
+	},
+	"erlang": func(flags map[string]string) (Generator, error) {
+		return NewGenStatemGenerator(Erlang, flags), nil
+	},
+	"mermaid": func(flags map[string]string) (Generator, error) {
+		return NewDiagramGenerator(Mermaid), nil
+	},
+	"plantuml": func(flags map[string]string) (Generator, error) {
+		return NewDiagramGenerator(PlantUML), nil
+	},
+	"scxml": func(flags map[string]string) (Generator, error) {
+		return NewSCXMLGenerator(), nil
+	},
+	"sql": func(flags map[string]string) (Generator, error) {
+		return NewSQLGenerator(flags)
+	},
+	"template": func(flags map[string]string) (Generator, error) {
+		return NewTemplateGenerator(flags)
+	},
+	"verilog": func(flags map[string]string) (Generator, error) {
+		return NewHDLGenerator(Verilog, flags)
+	},
+	"vhdl": func(flags map[string]string) (Generator, error) {
+		return NewHDLGenerator(VHDL, flags)
+	},
+}
+
+// nestedSwitchCaseGenerator and tableGenerator make a new language code
+// generator for every machine, as the implementors append to their output.
+type nestedSwitchCaseGenerator struct {
+	factory LanguageCodeGeneratorFactory
+	flags   map[string]string
+}
+
+func (nscGenerator nestedSwitchCaseGenerator) Generate(machine *StateMachine) ([]Artifact, error) {
+	return NewCodeGenerator(machine.Optimized, nscGenerator.factory(nscGenerator.flags)).Generate()
+}
+
+type tableGenerator struct {
+	factory TableLanguageCodeGeneratorFactory
+	flags   map[string]string
+}
+
+func (tableGenerator tableGenerator) Generate(machine *StateMachine) ([]Artifact, error) {
+	return NewTableCodeGenerator(machine.Optimized, tableGenerator.factory(tableGenerator.flags)).Generate()
+}
+
+// NewGenerator looks the language up in the registries. The strategy flag
+// picks how state machine code is generated: "switch" (the default) nests
+// switch statements on the state and event, "table" looks transitions up in
+// arrays, which keeps large machines compact, and "state" generates a class
+// per state that keeps the superstate hierarchy.
+func NewGenerator(language string, flags map[string]string) (Generator, error) {
+	switch strategy := flags["strategy"]; strategy {
+	case "", "switch":
+	case "table":
+		factory, ok := tableCodeGenerators[language]
+		if !ok {
+			return nil, fmt.Errorf("generator %q does not support strategy=table", language)
+		}
+		return tableGenerator{factory: factory, flags: flags}, nil
+	case "state":
+		factory, ok := statePatternGenerators[language]
+		if !ok {
+			return nil, fmt.Errorf("generator %q does not support strategy=state", language)
+		}
+		return factory(flags)
+	default:
+		return nil, fmt.Errorf("unknown generation strategy %q, expected switch, table or state", strategy)
+	}
+	if factory, ok := languageCodeGenerators[language]; ok {
+		return nestedSwitchCaseGenerator{factory: factory, flags: flags}, nil
+	}
+	if factory, ok := generators[language]; ok {
+		return factory(flags)
+	}
+	return nil, fmt.Errorf("unknown generator %q", language)
+}
+
+func NewLanguageCodeGenerator(language string, flags map[string]string) (LanguageCodeGenerator, error) {
+	factory, ok := languageCodeGenerators[language]
+	if !ok {
+		return nil, fmt.Errorf("unknown generator %q", language)
+	}
+	return factory(flags), nil
+}
+
+func Languages() []string {
+	languages := make([]string, 0, len(languageCodeGenerators)+len(generators))
+	for language := range languageCodeGenerators {
+		languages = append(languages, language)
+	}
+	for language := range generators {
+		languages = append(languages, language)
+	}
+	sort.Strings(languages)
+	return languages
+}
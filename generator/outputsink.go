@@ -0,0 +1,134 @@
+package generator
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// OutputSink receives generated artifacts. Artifact names are slash
+// separated paths relative to the root of the sink.
+type OutputSink interface {
+	Write(artifact Artifact) error
+	Close() error
+}
+
+type DiskSink struct {
+	directory string
+}
+
+func NewDiskSink(directory string) *DiskSink {
+	return &DiskSink{directory: directory}
+}
+
+func (sink *DiskSink) Write(artifact Artifact) error {
+	filePath, err := artifactPath(sink.directory, artifact)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(filePath, []byte(artifact.Content), 0644)
+}
+
+func (sink *DiskSink) Close() error {
+	return nil
+}
+
+type MemorySink struct {
+	Artifacts map[string]string
+}
+
+func NewMemorySink() *MemorySink {
+	return &MemorySink{Artifacts: make(map[string]string)}
+}
+
+func (sink *MemorySink) Write(artifact Artifact) error {
+	sink.Artifacts[artifact.Name] = artifact.Content
+	return nil
+}
+
+func (sink *MemorySink) Close() error {
+	return nil
+}
+
+type ArchiveSink struct {
+	writer *zip.Writer
+}
+
+func NewArchiveSink(writer io.Writer) *ArchiveSink {
+	return &ArchiveSink{writer: zip.NewWriter(writer)}
+}
+
+func (sink *ArchiveSink) Write(artifact Artifact) error {
+	if _, err := artifactPath("", artifact); err != nil {
+		return err
+	}
+	file, err := sink.writer.Create(artifact.Name)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(file, artifact.Content)
+	return err
+}
+
+func (sink *ArchiveSink) Close() error {
+	return sink.writer.Close()
+}
+
+// CheckSink writes nothing. It records every artifact whose file under
+// directory is missing or differs from the generated content.
+type CheckSink struct {
+	directory  string
+	staleFiles []string
+}
+
+func NewCheckSink(directory string) *CheckSink {
+	return &CheckSink{directory: directory}
+}
+
+func (sink *CheckSink) Write(artifact Artifact) error {
+	filePath, err := artifactPath(sink.directory, artifact)
+	if err != nil {
+		return err
+	}
+	content, err := os.ReadFile(filePath)
+	if errors.Is(err, os.ErrNotExist) {
+		sink.staleFiles = append(sink.staleFiles, filePath)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(content, []byte(artifact.Content)) {
+		sink.staleFiles = append(sink.staleFiles, filePath)
+	}
+	return nil
+}
+
+func (sink *CheckSink) Close() error {
+	return nil
+}
+
+func (sink *CheckSink) GetStaleFiles() []string {
+	staleFiles := append([]string{}, sink.staleFiles...)
+	sort.Strings(staleFiles)
+	return staleFiles
+}
+
+var errArtifactOutsideSink = errors.New("artifact name escapes the output root")
+
+func artifactPath(directory string, artifact Artifact) (string, error) {
+	name := path.Clean(artifact.Name)
+	if name == "." || path.IsAbs(name) || name == ".." || strings.HasPrefix(name, "../") {
+		return "", &os.PathError{Op: "write", Path: artifact.Name, Err: errArtifactOutsideSink}
+	}
+	return filepath.Join(directory, filepath.FromSlash(name)), nil
+}
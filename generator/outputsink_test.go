@@ -0,0 +1,120 @@
+package generator
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+var artifacts = []Artifact{
+	{Name: "firsttry/TurnstileFSM.java", Content: "class TurnstileFSM {}\n"},
+	{Name: "README", Content: "generated\n"},
+}
+
+func TestDiskSink(t *testing.T) {
+	directory := t.TempDir()
+	sink := NewDiskSink(directory)
+	if err := WriteArtifacts(sink, artifacts); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, artifact := range artifacts {
+		content, err := os.ReadFile(filepath.Join(directory, filepath.FromSlash(artifact.Name)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(content) != artifact.Content {
+			t.Fatalf("expected '%s' in %s, but got '%s'", artifact.Content, artifact.Name, content)
+		}
+	}
+}
+
+func TestMemorySink(t *testing.T) {
+	sink := NewMemorySink()
+	if err := WriteArtifacts(sink, artifacts); err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]string{
+		"firsttry/TurnstileFSM.java": "class TurnstileFSM {}\n",
+		"README":                     "generated\n",
+	}
+	if !reflect.DeepEqual(sink.Artifacts, expected) {
+		t.Fatalf("expected %v, but got %v", expected, sink.Artifacts)
+	}
+}
+
+func TestArchiveSink(t *testing.T) {
+	var archive bytes.Buffer
+	sink := NewArchiveSink(&archive)
+	if err := WriteArtifacts(sink, artifacts); err != nil {
+		t.Fatal(err)
+	}
+	if err := sink.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	reader, err := zip.NewReader(bytes.NewReader(archive.Bytes()), int64(archive.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[string]string)
+	for _, file := range reader.File {
+		opened, err := file.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, err := io.ReadAll(opened)
+		opened.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		got[file.Name] = string(content)
+	}
+	if len(got) != 2 || got["firsttry/TurnstileFSM.java"] != artifacts[0].Content {
+		t.Fatalf("unexpected archive content %v", got)
+	}
+}
+
+func TestCheckSink(t *testing.T) {
+	directory := t.TempDir()
+	if err := WriteArtifacts(NewDiskSink(directory), artifacts[:1]); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("up to date", func(t *testing.T) {
+		sink := NewCheckSink(directory)
+		if err := WriteArtifacts(sink, artifacts[:1]); err != nil {
+			t.Fatal(err)
+		}
+		if len(sink.GetStaleFiles()) != 0 {
+			t.Fatalf("expected no stale files, but got %v", sink.GetStaleFiles())
+		}
+	})
+
+	t.Run("changed and missing files are stale", func(t *testing.T) {
+		sink := NewCheckSink(directory)
+		changed := Artifact{Name: artifacts[0].Name, Content: "class Changed {}\n"}
+		if err := WriteArtifacts(sink, []Artifact{changed, artifacts[1]}); err != nil {
+			t.Fatal(err)
+		}
+		expected := []string{
+			filepath.Join(directory, "README"),
+			filepath.Join(directory, "firsttry", "TurnstileFSM.java"),
+		}
+		if !reflect.DeepEqual(sink.GetStaleFiles(), expected) {
+			t.Fatalf("expected %v, but got %v", expected, sink.GetStaleFiles())
+		}
+	})
+}
+
+func TestArtifactNamesMustStayInsideTheSink(t *testing.T) {
+	for _, name := range []string{"../escape.java", "/abs.java", ""} {
+		if err := NewDiskSink(t.TempDir()).Write(Artifact{Name: name}); err == nil {
+			t.Fatalf("expected error for artifact name '%s'", name)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package generator
+
+import (
+	"strings"
+
+	"github.com/larkvincer/dsl-fsm/generator/implementors"
+	nscgenerator "github.com/larkvincer/dsl-fsm/generator/nestedswitchcasegenerator"
+)
+
+type PythonCodeGenerator struct {
+	pythonNestedSwitchCaseImplementor *implementors.PythonNestedSwitchCaseImplementor
+}
+
+func NewPythonCodeGenerator(
+	pythonNestedSwitchCaseImplementor *implementors.PythonNestedSwitchCaseImplementor,
+) *PythonCodeGenerator {
+	return &PythonCodeGenerator{
+		pythonNestedSwitchCaseImplementor: pythonNestedSwitchCaseImplementor,
+	}
+}
+
+func (pythonGenerator *PythonCodeGenerator) GetImplementer() nscgenerator.NSCNodeVisitor {
+	return pythonGenerator.pythonNestedSwitchCaseImplementor
+}
+
+func (pythonGenerator *PythonCodeGenerator) GetArtifacts(fsmName string) []Artifact {
+	return []Artifact{{
+		Name:    strings.ToLower(fsmName) + ".py",
+		Content: pythonGenerator.pythonNestedSwitchCaseImplementor.Output,
+	}}
+}
+
+func (pythonGenerator *PythonCodeGenerator) MemberName(name string) string {
+	return name
+}
+
+// FixedMembers are the members of the generated class besides the events
+// and actions. A later method of the same name would silently replace an
+// earlier one, so a collision would not even fail at import time.
+func (pythonGenerator *PythonCodeGenerator) FixedMembers(className string) map[string]bool {
+	return setOf("__init__", "state", "_state", "_setState", "unhandledTransition", "_unhandled_transition", "_handleEvent")
+}
@@ -0,0 +1,93 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+const pythonTurnstileMain = `import sys
+
+import twocointurnstile
+
+
+class Turnstile(twocointurnstile.TwoCoinTurnstile):
+    def alarmOff(self):
+        print("alarmOff")
+
+    def alarmOn(self):
+        print("alarmOn")
+
+    def lock(self):
+        print("lock")
+
+    def thankyou(self):
+        print("thankyou")
+
+    def unlock(self):
+        print("unlock")
+
+
+turnstile = Turnstile(lambda state, event: print("unhandled", state, event))
+for event in sys.argv[1:]:
+    getattr(turnstile, event)()
+print(turnstile.state.name)
+`
+
+func TestPythonArtifact(t *testing.T) {
+	artifacts := generate(t, twoCoinTurnstile, "python", map[string]string{})
+	if len(artifacts) != 1 || artifacts[0].Name != "twocointurnstile.py" {
+		t.Fatalf("unexpected artifacts %v", artifacts)
+	}
+	for _, expected := range []string{
+		"class State(enum.Enum):\n",
+		"class Turnstile(abc.ABC):\n    @abc.abstractmethod\n    def alarmOff(self):\n",
+		"class TwoCoinTurnstile(Turnstile):\n",
+		"    def Coin(self):\n        self._handleEvent(Event.Coin)\n",
+		"    def unhandledTransition(self, state, event):\n",
+	} {
+		if !strings.Contains(artifacts[0].Content, expected) {
+			t.Errorf("expected generated code to contain %q:\n%s", expected, artifacts[0].Content)
+		}
+	}
+}
+
+func TestPythonActionsWithoutActionsClass(t *testing.T) {
+	source := "FSM: f\nInitial: i\n{\ni e i a\n}\n"
+	artifacts := generate(t, source, "python", map[string]string{})
+	expected := "class f(abc.ABC):\n"
+	if !strings.Contains(artifacts[0].Content, expected) ||
+		!strings.HasSuffix(artifacts[0].Content, "    @abc.abstractmethod\n    def a(self):\n        pass\n") {
+		t.Fatalf("expected abstract actions on the FSM class:\n%s", artifacts[0].Content)
+	}
+}
+
+func TestPythonRejectsCollidingMembers(t *testing.T) {
+	for name, test := range map[string]struct{ source, message string }{
+		"event and action": {"FSM: f\nInitial: a\n{\n  a lock a lock\n}\n",
+			"event lock and action lock of f both become the method lock"},
+		"event and fixed member": {"FSM: f\nInitial: a\n{\n  a state a {}\n}\n",
+			"event state of f collides with the state member of the generated class"},
+		"action and fixed member": {"FSM: f\nInitial: a\n{\n  a e a _handleEvent\n}\n",
+			"action _handleEvent of f collides with the _handleEvent member of the generated class"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := generateError(t, test.source, "python", map[string]string{})
+			if err == nil || err.Error() != test.message {
+				t.Fatalf("expected %q, got %v", test.message, err)
+			}
+		})
+	}
+}
+
+func TestPythonArtifactRuns(t *testing.T) {
+	python := lookPath(t, "python3")
+	directory := t.TempDir()
+	artifacts := generate(t, twoCoinTurnstile, "python", map[string]string{})
+	artifacts = append(artifacts, Artifact{Name: "main.py", Content: pythonTurnstileMain})
+	writeArtifacts(t, directory, artifacts)
+
+	output := run(t, directory, python, append([]string{"main.py"}, twoCoinTurnstileEvents...)...)
+	if output != twoCoinTurnstileTrace {
+		t.Fatalf("expected\n%s\nbut got\n%s", twoCoinTurnstileTrace, output)
+	}
+}
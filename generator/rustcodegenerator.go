@@ -0,0 +1,29 @@
+package generator
+
+import (
+	"github.com/larkvincer/dsl-fsm/generator/implementors"
+	nscgenerator "github.com/larkvincer/dsl-fsm/generator/nestedswitchcasegenerator"
+)
+
+type RustCodeGenerator struct {
+	rustNestedSwitchCaseImplementor *implementors.RustNestedSwitchCaseImplementor
+}
+
+func NewRustCodeGenerator(
+	rustNestedSwitchCaseImplementor *implementors.RustNestedSwitchCaseImplementor,
+) *RustCodeGenerator {
+	return &RustCodeGenerator{
+		rustNestedSwitchCaseImplementor: rustNestedSwitchCaseImplementor,
+	}
+}
+
+func (rustGenerator *RustCodeGenerator) GetImplementer() nscgenerator.NSCNodeVisitor {
+	return rustGenerator.rustNestedSwitchCaseImplementor
+}
+
+func (rustGenerator *RustCodeGenerator) GetArtifacts(fsmName string) []Artifact {
+	return []Artifact{{
+		Name:    implementors.SnakeCase(fsmName) + ".rs",
+		Content: indentBlocks(rustGenerator.rustNestedSwitchCaseImplementor.Output, "    "),
+	}}
+}
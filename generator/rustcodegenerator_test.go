@@ -0,0 +1,70 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+const rustTurnstileMain = `mod two_coin_turnstile;
+
+use two_coin_turnstile::{Event, State, Turnstile, TwoCoinTurnstile};
+
+struct Printer;
+
+impl Turnstile for Printer {
+    fn alarm_off(&mut self) { println!("alarmOff"); }
+    fn alarm_on(&mut self) { println!("alarmOn"); }
+    fn lock(&mut self) { println!("lock"); }
+    fn thankyou(&mut self) { println!("thankyou"); }
+    fn unlock(&mut self) { println!("unlock"); }
+    fn unhandled_transition(&mut self, state: State, event: Event) {
+        println!("unhandled {:?} {:?}", state, event);
+    }
+}
+
+fn main() {
+    let mut turnstile = TwoCoinTurnstile::new(Printer);
+    for event in std::env::args().skip(1) {
+        match event.as_str() {
+            "Coin" => turnstile.coin(),
+            "Pass" => turnstile.pass(),
+            "Reset" => turnstile.reset(),
+            _ => panic!("unknown event {}", event),
+        }
+    }
+    println!("{:?}", turnstile.state());
+}
+`
+
+func TestRustArtifact(t *testing.T) {
+	artifacts := generate(t, twoCoinTurnstile, "rust", map[string]string{})
+	if len(artifacts) != 1 || artifacts[0].Name != "two_coin_turnstile.rs" {
+		t.Fatalf("unexpected artifacts %v", artifacts)
+	}
+	content := artifacts[0].Content
+	for _, expected := range []string{
+		"#[derive(Debug, Clone, Copy, PartialEq, Eq)]\npub enum State {\n    Alarming,\n",
+		"pub trait Turnstile {\n    fn alarm_off(&mut self);\n",
+		"pub struct TwoCoinTurnstile<A: Turnstile> {\n",
+		"    fn handle_event(&mut self, event: Event) {\n        match self.state {\n",
+		"_ => self.actions.unhandled_transition(self.state, event),\n",
+	} {
+		if !strings.Contains(content, expected) {
+			t.Errorf("expected generated code to contain %q:\n%s", expected, content)
+		}
+	}
+}
+
+func TestRustArtifactCompilesAndRuns(t *testing.T) {
+	rustc := lookPath(t, "rustc")
+	directory := t.TempDir()
+	artifacts := generate(t, twoCoinTurnstile, "rust", map[string]string{})
+	artifacts = append(artifacts, Artifact{Name: "main.rs", Content: rustTurnstileMain})
+	writeArtifacts(t, directory, artifacts)
+
+	run(t, directory, rustc, "--edition", "2018", "-A", "dead_code", "-o", "turnstile", "main.rs")
+	output := run(t, directory, directory+"/turnstile", twoCoinTurnstileEvents...)
+	if output != twoCoinTurnstileTrace {
+		t.Fatalf("expected\n%s\nbut got\n%s", twoCoinTurnstileTrace, output)
+	}
+}
@@ -0,0 +1,104 @@
+package generator
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+
+	"github.com/larkvincer/dsl-fsm/generator/implementors"
+	"github.com/larkvincer/dsl-fsm/scxml"
+	"github.com/larkvincer/dsl-fsm/semanticanalyzer"
+)
+
+// SCXMLGenerator writes the state machine as a W3C SCXML document that
+// scxml.Read reads back. Superstates become compound <state>s around their
+// substates, entry and exit actions go into <onentry> and <onexit>, and
+// every action is a <script> calling it, like "unlock();". The Actions
+// header is kept in an smc:actions attribute. SCXML nests every state in a
+// single parent and never rests in a compound state, so states with
+// several superstates and superstates that are not abstract are reported
+// as errors.
+type SCXMLGenerator struct {
+	output   strings.Builder
+	children map[*semanticanalyzer.SemanticState][]*semanticanalyzer.SemanticState
+}
+
+func NewSCXMLGenerator() *SCXMLGenerator {
+	return &SCXMLGenerator{}
+}
+
+func (scxmlGenerator *SCXMLGenerator) Generate(machine *StateMachine) ([]Artifact, error) {
+	ssm := machine.Semantic
+	out := &scxmlGenerator.output
+	out.Reset()
+	states := sortedSemanticStates(ssm)
+	var roots []*semanticanalyzer.SemanticState
+	roots, scxmlGenerator.children = stateTree(states)
+	for _, state := range states {
+		if len(state.SuperStates) > 1 {
+			return nil, fmt.Errorf("SCXML cannot express state %s with several superstates", state.Name)
+		}
+		if len(scxmlGenerator.children[state]) > 0 && !state.AbstractState {
+			return nil, fmt.Errorf("SCXML cannot express superstate %s that is not abstract", state.Name)
+		}
+	}
+
+	fmt.Fprintf(out, "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n")
+	fmt.Fprintf(out, "<!-- Generated by smc. Do not edit. -->\n")
+	fmt.Fprintf(out, "<scxml xmlns=\"%s\" version=\"1.0\"", scxml.Namespace)
+	if ssm.ActionClass != "" {
+		fmt.Fprintf(out, " xmlns:smc=\"%s\" smc:actions=%s", scxml.SMCNamespace, xmlAttribute(ssm.ActionClass))
+	}
+	fmt.Fprintf(out, " name=%s initial=%s>\n", xmlAttribute(ssm.FsmName), xmlAttribute(ssm.InitialState.Name))
+	for _, state := range roots {
+		scxmlGenerator.writeState(state, "  ")
+	}
+	fmt.Fprintf(out, "</scxml>\n")
+	return []Artifact{{Name: implementors.SnakeCase(ssm.FsmName) + ".scxml", Content: out.String()}}, nil
+}
+
+func (scxmlGenerator *SCXMLGenerator) writeState(state *semanticanalyzer.SemanticState, indent string) {
+	out := &scxmlGenerator.output
+	fmt.Fprintf(out, "%s<state id=%s>\n", indent, xmlAttribute(state.Name))
+	scxmlGenerator.writeScripts("onentry", state.EntryActions, indent+"  ")
+	scxmlGenerator.writeScripts("onexit", state.ExitActions, indent+"  ")
+	for _, transition := range state.Transitions {
+		// Like the optimizer, drop transitions without an event; SCXML
+		// would take them as soon as the state is entered.
+		if transition.Event == "" {
+			continue
+		}
+		target := " target=" + xmlAttribute(transition.NextState.Name)
+		if len(transition.Action) == 0 {
+			fmt.Fprintf(out, "%s  <transition event=%s%s/>\n", indent, xmlAttribute(transition.Event), target)
+			continue
+		}
+		fmt.Fprintf(out, "%s  <transition event=%s%s>\n", indent, xmlAttribute(transition.Event), target)
+		for _, action := range transition.Action {
+			fmt.Fprintf(out, "%s    <script>%s();</script>\n", indent, action)
+		}
+		fmt.Fprintf(out, "%s  </transition>\n", indent)
+	}
+	for _, child := range scxmlGenerator.children[state] {
+		scxmlGenerator.writeState(child, indent+"  ")
+	}
+	fmt.Fprintf(out, "%s</state>\n", indent)
+}
+
+func (scxmlGenerator *SCXMLGenerator) writeScripts(element string, actions []string, indent string) {
+	if len(actions) == 0 {
+		return
+	}
+	out := &scxmlGenerator.output
+	fmt.Fprintf(out, "%s<%s>\n", indent, element)
+	for _, action := range actions {
+		fmt.Fprintf(out, "%s  <script>%s();</script>\n", indent, action)
+	}
+	fmt.Fprintf(out, "%s</%s>\n", indent, element)
+}
+
+func xmlAttribute(value string) string {
+	escaped := &strings.Builder{}
+	xml.EscapeText(escaped, []byte(value))
+	return `"` + escaped.String() + `"`
+}
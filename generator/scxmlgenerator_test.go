@@ -0,0 +1,78 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/larkvincer/dsl-fsm/optimizer"
+	"github.com/larkvincer/dsl-fsm/scxml"
+	"github.com/larkvincer/dsl-fsm/semanticanalyzer"
+)
+
+func TestSCXMLGenerator(t *testing.T) {
+	artifacts := generate(t, twoCoinTurnstile, "scxml", map[string]string{})
+	if len(artifacts) != 1 || artifacts[0].Name != "two_coin_turnstile.scxml" {
+		t.Fatalf("unexpected artifacts %v", artifacts)
+	}
+	content := artifacts[0].Content
+	for _, expected := range []string{
+		"<scxml xmlns=\"http://www.w3.org/2005/07/scxml\" version=\"1.0\" " +
+			"xmlns:smc=\"https://github.com/larkvincer/dsl-fsm\" smc:actions=\"Turnstile\" " +
+			"name=\"TwoCoinTurnstile\" initial=\"Locked\">\n",
+		"  <state id=\"Base\">\n" +
+			"    <transition event=\"Reset\" target=\"Locked\">\n" +
+			"      <script>lock();</script>\n" +
+			"    </transition>\n" +
+			"    <state id=\"Alarming\">\n" +
+			"      <onentry>\n" +
+			"        <script>alarmOn();</script>\n" +
+			"      </onentry>\n" +
+			"      <onexit>\n" +
+			"        <script>alarmOff();</script>\n" +
+			"      </onexit>\n" +
+			"    </state>\n",
+		"      <transition event=\"Coin\" target=\"FirstCoin\"/>\n",
+	} {
+		if !strings.Contains(content, expected) {
+			t.Errorf("expected generated document to contain %q:\n%s", expected, content)
+		}
+	}
+}
+
+func TestSCXMLRoundTrip(t *testing.T) {
+	for name, source := range map[string]string{
+		"two coin turnstile": twoCoinTurnstile,
+		"superstate actions": "FSM: f\nInitial: a\n{\n  (s) <in { e a x }\n  a : s { f b {} }\n  b : s >out { f a {y z} }\n}\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			syntax, err := scxml.Read(strings.NewReader(generate(t, source, "scxml", map[string]string{})[0].Content))
+			if err != nil {
+				t.Fatal(err)
+			}
+			imported := semanticanalyzer.New().Analyze(syntax)
+			if len(imported.Errors) > 0 {
+				t.Fatalf("unexpected errors %v", imported.Errors)
+			}
+			expected := optimizer.Optimize(*analyze(t, source)).String()
+			if actual := optimizer.Optimize(*imported).String(); actual != expected {
+				t.Errorf("expected\n%s\ngot\n%s", expected, actual)
+			}
+		})
+	}
+}
+
+func TestSCXMLRejectsSeveralSuperStates(t *testing.T) {
+	ssm := analyze(t, multipleSuperStates)
+	_, err := NewSCXMLGenerator().Generate(&StateMachine{Semantic: ssm, Optimized: optimizer.Optimize(*ssm)})
+	if err == nil || !strings.Contains(err.Error(), "state i with several superstates") {
+		t.Fatalf("expected an error for several superstates, got %v", err)
+	}
+}
+
+func TestSCXMLRejectsConcreteSuperStates(t *testing.T) {
+	ssm := analyze(t, "FSM: f\nInitial: s\n{\n  s { e t {} }\n  t : s { f s {} }\n}\n")
+	_, err := NewSCXMLGenerator().Generate(&StateMachine{Semantic: ssm, Optimized: optimizer.Optimize(*ssm)})
+	if err == nil || !strings.Contains(err.Error(), "superstate s that is not abstract") {
+		t.Fatalf("expected an error for a concrete superstate, got %v", err)
+	}
+}
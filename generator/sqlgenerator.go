@@ -0,0 +1,159 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/larkvincer/dsl-fsm/generator/implementors"
+)
+
+type SQLDialect string
+
+const (
+	SQLite     SQLDialect = "sqlite"
+	PostgreSQL SQLDialect = "postgres"
+)
+
+// SQLGenerator emits a script that creates a lookup table of the states, a
+// table of the legal (from_state, event, to_state) transitions and triggers
+// that reject any insert of a status column other than the initial state
+// and any update of it that does not follow one of the transitions. The
+// flags select the dialect (sqlite or postgres), the table that holds the
+// status column (default: the snake_case FSM name) and the column itself
+// (default: state). That table has to exist before the script runs.
+type SQLGenerator struct {
+	dialect SQLDialect
+	table   string
+	column  string
+	flags   map[string]string
+	output  strings.Builder
+}
+
+func NewSQLGenerator(flags map[string]string) (*SQLGenerator, error) {
+	sqlGenerator := &SQLGenerator{
+		dialect: SQLite,
+		table:   flags["table"],
+		column:  flags["column"],
+		flags:   flags,
+	}
+	if dialect, ok := flags["dialect"]; ok {
+		sqlGenerator.dialect = SQLDialect(strings.ToLower(dialect))
+	}
+	if sqlGenerator.column == "" {
+		sqlGenerator.column = "state"
+	}
+	switch sqlGenerator.dialect {
+	case SQLite, PostgreSQL:
+		return sqlGenerator, nil
+	}
+	return nil, fmt.Errorf("unknown SQL dialect %q, expected sqlite or postgres", flags["dialect"])
+}
+
+func (sqlGenerator *SQLGenerator) Generate(machine *StateMachine) ([]Artifact, error) {
+	osm := machine.Optimized
+	if err := checkInitialState(osm); err != nil {
+		return nil, err
+	}
+	sqlGenerator.output.Reset()
+	out := &sqlGenerator.output
+	prefix := implementors.SnakeCase(osm.Header.Fsm)
+	table := sqlGenerator.table
+	if table == "" {
+		table = prefix
+	}
+	statesTable := prefix + "_states"
+	transitionsTable := prefix + "_transitions"
+
+	fmt.Fprintf(out, "-- Generated by smc. Do not edit.\n")
+	fmt.Fprintf(out, "-- Dialect: %s. Requires table %s with column %s.\n\n", sqlGenerator.dialect, table, sqlGenerator.column)
+
+	fmt.Fprintf(out, "CREATE TABLE %s (\n", statesTable)
+	fmt.Fprintf(out, "    name TEXT PRIMARY KEY,\n")
+	fmt.Fprintf(out, "    initial BOOLEAN NOT NULL DEFAULT FALSE\n")
+	fmt.Fprintf(out, ");\n\n")
+	fmt.Fprintf(out, "CREATE TABLE %s (\n", transitionsTable)
+	fmt.Fprintf(out, "    from_state TEXT NOT NULL REFERENCES %s (name),\n", statesTable)
+	fmt.Fprintf(out, "    event TEXT NOT NULL,\n")
+	fmt.Fprintf(out, "    to_state TEXT NOT NULL REFERENCES %s (name),\n", statesTable)
+	fmt.Fprintf(out, "    PRIMARY KEY (from_state, event)\n")
+	fmt.Fprintf(out, ");\n\n")
+
+	rows := []string{}
+	for _, state := range osm.States {
+		rows = append(rows, fmt.Sprintf("    (%s, %t)", sqlString(state), state == osm.Header.Initial))
+	}
+	if len(rows) > 0 {
+		fmt.Fprintf(out, "INSERT INTO %s (name, initial) VALUES\n", statesTable)
+		fmt.Fprintf(out, "%s;\n\n", strings.Join(rows, ",\n"))
+	}
+
+	rows = []string{}
+	for _, transition := range osm.Transitions {
+		for _, subTransition := range transition.SubTransitions {
+			rows = append(rows, fmt.Sprintf("    (%s, %s, %s)",
+				sqlString(transition.CurrentState), sqlString(subTransition.Event), sqlString(subTransition.NextState)))
+		}
+	}
+	if len(rows) > 0 {
+		fmt.Fprintf(out, "INSERT INTO %s (from_state, event, to_state) VALUES\n", transitionsTable)
+		fmt.Fprintf(out, "%s;\n\n", strings.Join(rows, ",\n"))
+	}
+
+	initial := fmt.Sprintf("SELECT 1 FROM %s WHERE name = NEW.%s AND initial", statesTable, sqlGenerator.column)
+	legal := fmt.Sprintf(
+		"SELECT 1 FROM %s WHERE from_state = OLD.%s AND to_state = NEW.%s",
+		transitionsTable, sqlGenerator.column, sqlGenerator.column,
+	)
+	if sqlGenerator.dialect == PostgreSQL {
+		function := prefix + "_check_initial_state"
+		fmt.Fprintf(out, "CREATE FUNCTION %s() RETURNS trigger AS $$\n", function)
+		fmt.Fprintf(out, "BEGIN\n")
+		fmt.Fprintf(out, "    IF NOT EXISTS (%s) THEN\n", initial)
+		fmt.Fprintf(out, "        RAISE EXCEPTION 'illegal %s initial state %%', NEW.%s;\n",
+			osm.Header.Fsm, sqlGenerator.column)
+		fmt.Fprintf(out, "    END IF;\n")
+		fmt.Fprintf(out, "    RETURN NEW;\n")
+		fmt.Fprintf(out, "END;\n")
+		fmt.Fprintf(out, "$$ LANGUAGE plpgsql;\n\n")
+		fmt.Fprintf(out, "CREATE TRIGGER %s_initial_state\n", prefix)
+		fmt.Fprintf(out, "BEFORE INSERT ON %s\n", table)
+		fmt.Fprintf(out, "FOR EACH ROW EXECUTE FUNCTION %s();\n\n", function)
+
+		function = prefix + "_check_transition"
+		fmt.Fprintf(out, "CREATE FUNCTION %s() RETURNS trigger AS $$\n", function)
+		fmt.Fprintf(out, "BEGIN\n")
+		fmt.Fprintf(out, "    IF NEW.%s IS DISTINCT FROM OLD.%s AND NOT EXISTS (%s) THEN\n",
+			sqlGenerator.column, sqlGenerator.column, legal)
+		fmt.Fprintf(out, "        RAISE EXCEPTION 'illegal %s transition from %% to %%', OLD.%s, NEW.%s;\n",
+			osm.Header.Fsm, sqlGenerator.column, sqlGenerator.column)
+		fmt.Fprintf(out, "    END IF;\n")
+		fmt.Fprintf(out, "    RETURN NEW;\n")
+		fmt.Fprintf(out, "END;\n")
+		fmt.Fprintf(out, "$$ LANGUAGE plpgsql;\n\n")
+		fmt.Fprintf(out, "CREATE TRIGGER %s_transition\n", prefix)
+		fmt.Fprintf(out, "BEFORE UPDATE OF %s ON %s\n", sqlGenerator.column, table)
+		fmt.Fprintf(out, "FOR EACH ROW EXECUTE FUNCTION %s();\n", function)
+	} else {
+		fmt.Fprintf(out, "CREATE TRIGGER %s_initial_state\n", prefix)
+		fmt.Fprintf(out, "BEFORE INSERT ON %s\n", table)
+		fmt.Fprintf(out, "FOR EACH ROW\n")
+		fmt.Fprintf(out, "WHEN NOT EXISTS (%s)\n", initial)
+		fmt.Fprintf(out, "BEGIN\n")
+		fmt.Fprintf(out, "    SELECT RAISE(ABORT, 'illegal %s initial state');\n", osm.Header.Fsm)
+		fmt.Fprintf(out, "END;\n\n")
+		fmt.Fprintf(out, "CREATE TRIGGER %s_transition\n", prefix)
+		fmt.Fprintf(out, "BEFORE UPDATE OF %s ON %s\n", sqlGenerator.column, table)
+		fmt.Fprintf(out, "FOR EACH ROW\n")
+		fmt.Fprintf(out, "WHEN NEW.%s IS NOT OLD.%s AND NOT EXISTS (%s)\n",
+			sqlGenerator.column, sqlGenerator.column, legal)
+		fmt.Fprintf(out, "BEGIN\n")
+		fmt.Fprintf(out, "    SELECT RAISE(ABORT, 'illegal %s transition');\n", osm.Header.Fsm)
+		fmt.Fprintf(out, "END;\n")
+	}
+
+	return []Artifact{{Name: prefix + ".sql", Content: sqlGenerator.output.String()}}, nil
+}
+
+func sqlString(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
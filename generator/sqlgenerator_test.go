@@ -0,0 +1,114 @@
+package generator
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestSQLiteGenerator(t *testing.T) {
+	artifacts := generate(t, twoCoinTurnstile, "sql", map[string]string{})
+	if len(artifacts) != 1 || artifacts[0].Name != "two_coin_turnstile.sql" {
+		t.Fatalf("unexpected artifacts %v", artifacts)
+	}
+	content := artifacts[0].Content
+	for _, expected := range []string{
+		"CREATE TABLE two_coin_turnstile_states (\n",
+		"    ('FirstCoin', false),\n    ('Locked', true),\n",
+		"    ('Locked', 'Pass', 'Alarming'),\n",
+		"    ('Unlocked', 'Coin', 'Unlocked'),\n",
+		"CREATE TRIGGER two_coin_turnstile_initial_state\nBEFORE INSERT ON two_coin_turnstile\n",
+		"WHEN NOT EXISTS (SELECT 1 FROM two_coin_turnstile_states WHERE name = NEW.state AND initial)\n",
+		"CREATE TRIGGER two_coin_turnstile_transition\nBEFORE UPDATE OF state ON two_coin_turnstile\n",
+		"WHEN NEW.state IS NOT OLD.state AND NOT EXISTS (" +
+			"SELECT 1 FROM two_coin_turnstile_transitions WHERE from_state = OLD.state AND to_state = NEW.state)\n",
+		"    SELECT RAISE(ABORT, 'illegal TwoCoinTurnstile transition');\n",
+	} {
+		if !strings.Contains(content, expected) {
+			t.Errorf("expected generated code to contain %q:\n%s", expected, content)
+		}
+	}
+}
+
+func TestPostgreSQLGenerator(t *testing.T) {
+	flags := map[string]string{"dialect": "postgres", "table": "gates", "column": "status"}
+	content := generate(t, twoCoinTurnstile, "sql", flags)[0].Content
+	for _, expected := range []string{
+		"CREATE FUNCTION two_coin_turnstile_check_transition() RETURNS trigger AS $$\n",
+		"    IF NEW.status IS DISTINCT FROM OLD.status AND NOT EXISTS (" +
+			"SELECT 1 FROM two_coin_turnstile_transitions WHERE from_state = OLD.status AND to_state = NEW.status) THEN\n",
+		"        RAISE EXCEPTION 'illegal TwoCoinTurnstile transition from % to %', OLD.status, NEW.status;\n",
+		"BEFORE UPDATE OF status ON gates\nFOR EACH ROW EXECUTE FUNCTION two_coin_turnstile_check_transition();\n",
+		"    IF NOT EXISTS (SELECT 1 FROM two_coin_turnstile_states WHERE name = NEW.status AND initial) THEN\n",
+		"BEFORE INSERT ON gates\nFOR EACH ROW EXECUTE FUNCTION two_coin_turnstile_check_initial_state();\n",
+	} {
+		if !strings.Contains(content, expected) {
+			t.Errorf("expected generated code to contain %q:\n%s", expected, content)
+		}
+	}
+}
+
+func TestSQLUnknownDialect(t *testing.T) {
+	if _, err := NewGenerator("sql", map[string]string{"dialect": "oracle"}); err == nil {
+		t.Fatal("expected an error for an unknown SQL dialect")
+	}
+}
+
+func TestSQLRejectsAbstractInitialState(t *testing.T) {
+	source := "FSM: f\nInitial: b\n{\n  (b) e c {}\n  c : b f c {}\n}\n"
+	if err := generateError(t, source, "sql", map[string]string{}); err == nil ||
+		err.Error() != "initial state b of f is abstract" {
+		t.Fatalf("expected an error for the abstract initial state, got %v", err)
+	}
+}
+
+func TestSQLiteTriggersRejectIllegalStates(t *testing.T) {
+	database, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	exec := func(statement string) error {
+		_, err := database.Exec(statement)
+		return err
+	}
+	if err := exec("CREATE TABLE two_coin_turnstile (id INTEGER PRIMARY KEY, state TEXT);"); err != nil {
+		if strings.Contains(err.Error(), "CGO_ENABLED=0") {
+			t.Skip("the embedded SQLite needs cgo")
+		}
+		t.Fatal(err)
+	}
+	if err := exec(generate(t, twoCoinTurnstile, "sql", map[string]string{})[0].Content); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, state := range []string{"'Unlocked'", "'bogus'", "NULL"} {
+		err := exec("INSERT INTO two_coin_turnstile (id, state) VALUES (2, " + state + ");")
+		if err == nil || !strings.Contains(err.Error(), "illegal TwoCoinTurnstile initial state") {
+			t.Fatalf("expected inserting %s to be rejected, got %v", state, err)
+		}
+	}
+	if err := exec("INSERT INTO two_coin_turnstile (id, state) " +
+		"SELECT 1, name FROM two_coin_turnstile_states WHERE initial;"); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, state := range []string{"FirstCoin", "Unlocked", "Unlocked", "Locked", "Alarming", "Locked"} {
+		if err := exec("UPDATE two_coin_turnstile SET state = '" + state + "' WHERE id = 1;"); err != nil {
+			t.Fatalf("expected the update to %s to pass, got %v", state, err)
+		}
+	}
+	var state string
+	if err := database.QueryRow("SELECT state FROM two_coin_turnstile;").Scan(&state); err != nil || state != "Locked" {
+		t.Fatalf("expected Locked, got %q, %v", state, err)
+	}
+
+	for _, state := range []string{"'Unlocked'", "'bogus'", "NULL"} {
+		err := exec("UPDATE two_coin_turnstile SET state = " + state + " WHERE id = 1;")
+		if err == nil || !strings.Contains(err.Error(), "illegal TwoCoinTurnstile transition") {
+			t.Fatalf("expected Locked -> %s to be rejected, got %v", state, err)
+		}
+	}
+}
@@ -0,0 +1,273 @@
+package generator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/larkvincer/dsl-fsm/generator/implementors"
+	"github.com/larkvincer/dsl-fsm/optimizer"
+	"github.com/larkvincer/dsl-fsm/semanticanalyzer"
+)
+
+// StatePatternGenerator emits a Java class that implements the GoF State
+// pattern, selected with the flag strategy=state. Every state becomes a
+// nested class that overrides only the events it handles; a superstate
+// becomes its base class, so its transitions are inherited rather than
+// copied. Java has no multiple inheritance, so a state with several
+// superstates extends the first one by name and copies whatever it would
+// inherit from the others. Entry and exit actions run in the order the
+// optimizer gives them: exit actions of the current state's hierarchy leaf
+// first, entry actions of the next state's hierarchy root first, then the
+// transition's own actions. The events name, enter and exit would override
+// the methods of the State base class and are rejected, as are events and
+// actions whose methods collide in the generated class and an abstract
+// initial state.
+type StatePatternGenerator struct {
+	flags       map[string]string
+	javaPackage string
+	className   string
+	output      strings.Builder
+}
+
+// transitionSource is the transition an event resolves to in some state,
+// together with the state that declares it.
+type transitionSource struct {
+	state      *semanticanalyzer.SemanticState
+	transition semanticanalyzer.SemanticTransition
+	index      int
+}
+
+func NewStatePatternGenerator(flags map[string]string) *StatePatternGenerator {
+	return &StatePatternGenerator{
+		flags:       flags,
+		javaPackage: flags["package"],
+	}
+}
+
+// stateMethods are the methods of the generated State base class besides
+// the events.
+var stateMethods = map[string]bool{"name": true, "enter": true, "exit": true}
+
+func (statePattern *StatePatternGenerator) MemberName(name string) string {
+	return name
+}
+
+// FixedMembers are the methods of the generated class besides the events
+// and actions.
+func (statePattern *StatePatternGenerator) FixedMembers(className string) map[string]bool {
+	return setOf("unhandledTransition", "setState")
+}
+
+func (statePattern *StatePatternGenerator) Generate(machine *StateMachine) ([]Artifact, error) {
+	ssm := machine.Semantic
+	if err := checkInitialState(machine.Optimized); err != nil {
+		return nil, err
+	}
+	for _, event := range sortedKeys(ssm.Events) {
+		if stateMethods[event] {
+			return nil, fmt.Errorf("event %s of %s collides with the %s() method of the generated State class",
+				event, ssm.FsmName, event)
+		}
+	}
+	if err := checkMembers(ssm.FsmName, sortedKeys(ssm.Events), sortedKeys(ssm.Actions), statePattern); err != nil {
+		return nil, err
+	}
+	statePattern.output.Reset()
+	statePattern.className = ssm.FsmName
+	out := &statePattern.output
+	states := sortedSemanticStates(ssm)
+	events := sortedKeys(ssm.Events)
+
+	if statePattern.javaPackage != "" {
+		fmt.Fprintf(out, "package %s;\n\n", statePattern.javaPackage)
+	}
+	if ssm.ActionClass != "" {
+		fmt.Fprintf(out, "public abstract class %s implements %s {\n", ssm.FsmName, ssm.ActionClass)
+	} else {
+		fmt.Fprintf(out, "public abstract class %s {\n", ssm.FsmName)
+	}
+	fmt.Fprintf(out, "public abstract void unhandledTransition(String state, String event);\n\n")
+	for _, state := range states {
+		if !state.AbstractState {
+			fmt.Fprintf(out, "private static final State %s = new %s();\n", stateConstantName(state), stateClassName(state))
+		}
+	}
+	fmt.Fprintf(out, "\nprivate State state = %s;\n\n", stateConstantName(&ssm.InitialState))
+	fmt.Fprintf(out, "private void setState(State s) { state = s; }\n\n")
+	for _, event := range events {
+		fmt.Fprintf(out, "public void %s() {\nstate.%s(this);\n}\n\n", event, event)
+	}
+	if ssm.ActionClass == "" {
+		for _, action := range sortedKeys(ssm.Actions) {
+			fmt.Fprintf(out, "protected abstract void %s();\n", action)
+		}
+		out.WriteString("\n")
+	}
+
+	fmt.Fprintf(out, "private abstract static class State {\n")
+	fmt.Fprintf(out, "abstract String name();\n\n")
+	fmt.Fprintf(out, "void enter(%s fsm) {}\n\n", ssm.FsmName)
+	fmt.Fprintf(out, "void exit(%s fsm) {}\n", ssm.FsmName)
+	for _, event := range events {
+		fmt.Fprintf(out, "\nvoid %s(%s fsm) {\nfsm.unhandledTransition(name(), %q);\n}\n", event, ssm.FsmName, event)
+	}
+	fmt.Fprintf(out, "}\n")
+
+	for _, state := range states {
+		statePattern.generateStateClass(state)
+	}
+	fmt.Fprintf(out, "}\n")
+
+	return []Artifact{{
+		Name:    packagePath(statePattern.javaPackage, ".") + ssm.FsmName + ".java",
+		Content: indentBlocks(statePattern.output.String(), "    "),
+	}}, nil
+}
+
+func (statePattern *StatePatternGenerator) generateStateClass(state *semanticanalyzer.SemanticState) {
+	out := &statePattern.output
+	baseClass := "State"
+	var base *semanticanalyzer.SemanticState
+	if superStates := sortedSuperStatesOf(state); len(superStates) > 0 {
+		base = superStates[0]
+		baseClass = stateClassName(base)
+	}
+
+	out.WriteString("\nprivate ")
+	if state.AbstractState {
+		out.WriteString("abstract ")
+	}
+	fmt.Fprintf(out, "static class %s extends %s {\n", stateClassName(state), baseClass)
+	members := []string{}
+	if !state.AbstractState {
+		members = append(members, fmt.Sprintf("@Override\nString name() {\nreturn %q;\n}\n", state.Name))
+		members = append(members, statePattern.stateActions(state)...)
+	}
+
+	inherited := map[string]transitionSource{}
+	if base != nil {
+		inherited = resolveTransitions(base)
+	}
+	resolved := resolveTransitions(state)
+	for _, event := range sortedTransitionEvents(resolved) {
+		source := resolved[event]
+		if inheritedSource, ok := inherited[event]; ok &&
+			inheritedSource.state == source.state && inheritedSource.index == source.index {
+			continue
+		}
+		members = append(members, statePattern.transitionMethod(source.transition))
+	}
+	out.WriteString(strings.Join(members, "\n"))
+	fmt.Fprintf(out, "}\n")
+}
+
+// stateActions overrides enter and exit with the actions of the whole
+// hierarchy, in the order of optimizer.SubTransitionOptimizer.
+func (statePattern *StatePatternGenerator) stateActions(state *semanticanalyzer.SemanticState) []string {
+	methods := []string{}
+	for _, method := range []struct {
+		name    string
+		actions []string
+	}{{"enter", entryActions(state)}, {"exit", exitActions(state)}} {
+		if len(method.actions) == 0 {
+			continue
+		}
+		body := fmt.Sprintf("@Override\nvoid %s(%s fsm) {\n", method.name, statePattern.className)
+		for _, action := range method.actions {
+			body += fmt.Sprintf("fsm.%s();\n", action)
+		}
+		methods = append(methods, body+"}\n")
+	}
+	return methods
+}
+
+func entryActions(state *semanticanalyzer.SemanticState) []string {
+	actions := []string{}
+	for _, stateInHierarchy := range optimizer.RootFirstHierarchy(state) {
+		actions = append(actions, stateInHierarchy.EntryActions...)
+	}
+	return actions
+}
+
+func exitActions(state *semanticanalyzer.SemanticState) []string {
+	actions := []string{}
+	hierarchy := optimizer.RootFirstHierarchy(state)
+	for i := len(hierarchy) - 1; i >= 0; i-- {
+		actions = append(actions, hierarchy[i].ExitActions...)
+	}
+	return actions
+}
+
+// transitionMethod switches to the next state before any action runs, as
+// the generated switch/case code does.
+func (statePattern *StatePatternGenerator) transitionMethod(transition semanticanalyzer.SemanticTransition) string {
+	nextState := stateConstantName(transition.NextState)
+	method := fmt.Sprintf("@Override\nvoid %s(%s fsm) {\n", transition.Event, statePattern.className)
+	method += fmt.Sprintf("fsm.setState(%s);\n", nextState)
+	method += "exit(fsm);\n"
+	method += fmt.Sprintf("%s.enter(fsm);\n", nextState)
+	for _, action := range transition.Action {
+		method += fmt.Sprintf("fsm.%s();\n", action)
+	}
+	return method + "}\n"
+}
+
+// resolveTransitions finds the transition each event resolves to in state,
+// overriding superstates the way optimizer.StateOptimizer does.
+func resolveTransitions(state *semanticanalyzer.SemanticState) map[string]transitionSource {
+	resolved := map[string]transitionSource{}
+	hierarchy := optimizer.RootFirstHierarchy(state)
+	for i := len(hierarchy) - 1; i >= 0; i-- {
+		for index, transition := range hierarchy[i].Transitions {
+			if _, ok := resolved[transition.Event]; !ok && transition.Event != "" {
+				resolved[transition.Event] = transitionSource{state: hierarchy[i], transition: transition, index: index}
+			}
+		}
+	}
+	return resolved
+}
+
+func sortedTransitionEvents(transitions map[string]transitionSource) []string {
+	events := []string{}
+	for event := range transitions {
+		events = append(events, event)
+	}
+	sort.Strings(events)
+	return events
+}
+
+func sortedSemanticStates(ssm *semanticanalyzer.SemanticStateMachine) []*semanticanalyzer.SemanticState {
+	states := []*semanticanalyzer.SemanticState{}
+	for _, state := range ssm.States {
+		states = append(states, state)
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i].Name < states[j].Name })
+	return states
+}
+
+func sortedSuperStatesOf(state *semanticanalyzer.SemanticState) []*semanticanalyzer.SemanticState {
+	superStates := []*semanticanalyzer.SemanticState{}
+	for superState := range state.SuperStates {
+		superStates = append(superStates, superState)
+	}
+	sort.Slice(superStates, func(i, j int) bool { return superStates[i].Name < superStates[j].Name })
+	return superStates
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := []string{}
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func stateClassName(state *semanticanalyzer.SemanticState) string {
+	return strings.ToUpper(state.Name[:1]) + state.Name[1:] + "State"
+}
+
+func stateConstantName(state *semanticanalyzer.SemanticState) string {
+	return strings.ToUpper(implementors.SnakeCase(state.Name))
+}
@@ -0,0 +1,156 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/larkvincer/dsl-fsm/optimizer"
+)
+
+const multipleSuperStates = "" +
+	"FSM: Machine\n" +
+	"Initial: i\n" +
+	"{\n" +
+	"  (b1) <e1 >x1 { r i a }\n" +
+	"  (b2) : b1 <e2 >x2 { s i {} }\n" +
+	"  (b3) <e3 >x3 { u j c }\n" +
+	"  i : b2 : b3 <ei >xi { t j d }\n" +
+	"  j : b1 { t i {} }\n" +
+	"}\n"
+
+func TestStatePatternStrategy(t *testing.T) {
+	artifacts := generate(t, twoCoinTurnstile, "java", map[string]string{"package": "turnstile", "strategy": "state"})
+	if len(artifacts) != 1 || artifacts[0].Name != "turnstile/TwoCoinTurnstile.java" {
+		t.Fatalf("unexpected artifacts %v", artifacts)
+	}
+	content := artifacts[0].Content
+	for _, expected := range []string{
+		"public abstract class TwoCoinTurnstile implements Turnstile {\n",
+		"    private State state = LOCKED;\n",
+		"    public void Coin() {\n        state.Coin(this);\n    }\n",
+		"        void Pass(TwoCoinTurnstile fsm) {\n            fsm.unhandledTransition(name(), \"Pass\");\n        }\n",
+		"    private abstract static class BaseState extends State {\n" +
+			"        @Override\n" +
+			"        void Reset(TwoCoinTurnstile fsm) {\n" +
+			"            fsm.setState(LOCKED);\n" +
+			"            exit(fsm);\n" +
+			"            LOCKED.enter(fsm);\n" +
+			"            fsm.lock();\n" +
+			"        }\n" +
+			"    }\n",
+		"    private static class AlarmingState extends BaseState {\n" +
+			"        @Override\n" +
+			"        String name() {\n" +
+			"            return \"Alarming\";\n" +
+			"        }\n\n" +
+			"        @Override\n" +
+			"        void enter(TwoCoinTurnstile fsm) {\n" +
+			"            fsm.alarmOn();\n" +
+			"        }\n\n" +
+			"        @Override\n" +
+			"        void exit(TwoCoinTurnstile fsm) {\n" +
+			"            fsm.alarmOff();\n" +
+			"        }\n" +
+			"    }\n",
+		"        void Coin(TwoCoinTurnstile fsm) {\n" +
+			"            fsm.setState(UNLOCKED);\n" +
+			"            exit(fsm);\n" +
+			"            UNLOCKED.enter(fsm);\n" +
+			"            fsm.thankyou();\n" +
+			"        }\n",
+	} {
+		if !strings.Contains(content, expected) {
+			t.Errorf("expected generated code to contain %q:\n%s", expected, content)
+		}
+	}
+	if strings.Count(content, "void Reset(TwoCoinTurnstile fsm)") != 2 {
+		t.Errorf("expected Reset to be declared only by State and BaseState:\n%s", content)
+	}
+}
+
+func TestStatePatternWithMultipleSuperStates(t *testing.T) {
+	content := generate(t, multipleSuperStates, "java", map[string]string{"strategy": "state"})[0].Content
+	for _, expected := range []string{
+		"public abstract class Machine {\n",
+		"    protected abstract void a();\n",
+		"    private static class IState extends B2State {\n",
+		"        void enter(Machine fsm) {\n            fsm.e1();\n            fsm.e2();\n            fsm.e3();\n            fsm.ei();\n        }\n",
+		"        void exit(Machine fsm) {\n            fsm.xi();\n            fsm.x3();\n            fsm.x2();\n            fsm.x1();\n        }\n",
+		"        void u(Machine fsm) {\n            fsm.setState(J);\n            exit(fsm);\n            J.enter(fsm);\n            fsm.c();\n        }\n",
+	} {
+		if !strings.Contains(content, expected) {
+			t.Errorf("expected generated code to contain %q:\n%s", expected, content)
+		}
+	}
+	iState := content[strings.Index(content, "class IState"):]
+	if strings.Contains(iState, "void s(Machine fsm)") {
+		t.Errorf("expected IState to inherit s from B2State:\n%s", content)
+	}
+}
+
+// TestStatePatternMatchesOptimizer checks that every concrete state resolves
+// its events to the next states and actions the optimizer computes.
+func TestStatePatternMatchesOptimizer(t *testing.T) {
+	for _, source := range []string{twoCoinTurnstile, multipleSuperStates} {
+		semanticStateMachine := analyze(t, source)
+		osm := optimizer.Optimize(*semanticStateMachine)
+		for _, transition := range osm.Transitions {
+			state := semanticStateMachine.States[transition.CurrentState]
+			resolved := resolveTransitions(state)
+			if len(resolved) != len(transition.SubTransitions) {
+				t.Errorf("%s: expected %d events, got %d", state.Name, len(transition.SubTransitions), len(resolved))
+			}
+			for _, subTransition := range transition.SubTransitions {
+				source := resolved[subTransition.Event]
+				nextState := source.transition.NextState
+				actions := append(exitActions(state), entryActions(nextState)...)
+				actions = append(actions, source.transition.Action...)
+				if nextState.Name != subTransition.NextState || strings.Join(actions, " ") != strings.Join(subTransition.Actions, " ") {
+					t.Errorf("%s %s: expected %s %v, got %s %v", state.Name, subTransition.Event,
+						subTransition.NextState, subTransition.Actions, nextState.Name, actions)
+				}
+			}
+		}
+	}
+}
+
+func TestStatePatternRejectsWhatJavaCannotExpress(t *testing.T) {
+	for name, test := range map[string]struct{ source, message string }{
+		"abstract initial state": {
+			"FSM: f\nInitial: b\n{\n  (b) e c {}\n  c : b f c {}\n}\n",
+			"initial state b of f is abstract",
+		},
+		"event enter": {
+			"FSM: f\nInitial: a\n{\n  a enter a {}\n}\n",
+			"event enter of f collides with the enter() method",
+		},
+		"event name": {
+			"FSM: f\nInitial: a\n{\n  a name a {}\n}\n",
+			"event name of f collides with the name() method",
+		},
+		"event and action": {
+			"FSM: f\nInitial: a\n{\n  a lock a lock\n}\n",
+			"event lock and action lock of f both become the method lock",
+		},
+		"entry action and fixed member": {
+			"FSM: f\nInitial: a\n{\n  a <setState e a {}\n}\n",
+			"action setState of f collides with the setState member of the generated class",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			ssm := analyze(t, test.source)
+			_, err := NewStatePatternGenerator(map[string]string{}).Generate(
+				&StateMachine{Semantic: ssm, Optimized: optimizer.Optimize(*ssm)},
+			)
+			if err == nil || !strings.Contains(err.Error(), test.message) {
+				t.Fatalf("expected an error containing %q, got %v", test.message, err)
+			}
+		})
+	}
+}
+
+func TestStatePatternIsJavaOnly(t *testing.T) {
+	if _, err := NewGenerator("go", map[string]string{"strategy": "state"}); err == nil {
+		t.Fatal("expected an error for a language without strategy=state")
+	}
+}
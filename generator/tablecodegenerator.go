@@ -0,0 +1,48 @@
+package generator
+
+import (
+	ttgenerator "github.com/larkvincer/dsl-fsm/generator/transitiontablegenerator"
+	"github.com/larkvincer/dsl-fsm/optimizer"
+)
+
+// TableLanguageCodeGenerator is the table driven counterpart of
+// LanguageCodeGenerator, selected with the flag strategy=table.
+type TableLanguageCodeGenerator interface {
+	GetTableImplementer() ttgenerator.TransitionTableImplementor
+	GetArtifacts(fsmName string) []Artifact
+}
+
+type TableCodeGenerator struct {
+	optimizedStateMachine      *optimizer.OptimizedStateMachine
+	tableLanguageCodeGenerator TableLanguageCodeGenerator
+}
+
+func NewTableCodeGenerator(
+	osm *optimizer.OptimizedStateMachine,
+	tableLanguageCodeGenerator TableLanguageCodeGenerator,
+) *TableCodeGenerator {
+	return &TableCodeGenerator{
+		optimizedStateMachine:      osm,
+		tableLanguageCodeGenerator: tableLanguageCodeGenerator,
+	}
+}
+
+func (tcg *TableCodeGenerator) Generate() ([]Artifact, error) {
+	if err := checkInitialState(tcg.optimizedStateMachine); err != nil {
+		return nil, err
+	}
+	if memberGenerator, ok := tcg.tableLanguageCodeGenerator.(MemberGenerator); ok {
+		osm := tcg.optimizedStateMachine
+		if err := checkMembers(osm.Header.Fsm, osm.Events, osm.Actions, memberGenerator); err != nil {
+			return nil, err
+		}
+	}
+	implementor := tcg.tableLanguageCodeGenerator.GetTableImplementer()
+	ttGenerator := ttgenerator.TTGenerator{}
+	table, err := ttGenerator.Generate(tcg.optimizedStateMachine)
+	if err != nil {
+		return nil, err
+	}
+	implementor.Implement(table)
+	return tcg.tableLanguageCodeGenerator.GetArtifacts(tcg.optimizedStateMachine.Header.Fsm), nil
+}
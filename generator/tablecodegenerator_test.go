@@ -0,0 +1,115 @@
+package generator
+
+import (
+	"go/format"
+	"strings"
+	"testing"
+)
+
+func TestGoTableStrategy(t *testing.T) {
+	artifacts := generate(t, twoCoinTurnstile, "go", map[string]string{"package": "turnstile", "strategy": "table"})
+	if len(artifacts) != 1 || artifacts[0].Name != "twocointurnstile.go" {
+		t.Fatalf("unexpected artifacts %v", artifacts)
+	}
+	content := artifacts[0].Content
+	if formatted, err := format.Source([]byte(content)); err != nil || string(formatted) != content {
+		t.Fatalf("generated code is not gofmt-clean (%v):\n%s", err, content)
+	}
+	for _, expected := range []string{
+		"var twoCoinTurnstileNextStates = [4][3]int{\n\t{-1, -1, 2},\n\t{3, 0, 2},\n\t{1, 0, 2},\n\t{3, 2, 2},\n}\n",
+		"var twoCoinTurnstileActionListIndices = [4][3]int{\n\t{0, 0, 1},\n",
+		"var twoCoinTurnstileActionLists = [][]int{\n\t{},\n\t{0, 2},\n",
+		"var twoCoinTurnstileActions = [...]func(Turnstile){\n\tTurnstile.AlarmOff,\n",
+		"\tnext := twoCoinTurnstileNextStates[fsm.state][event]\n",
+	} {
+		if !strings.Contains(content, expected) {
+			t.Errorf("expected generated code to contain %q:\n%s", expected, content)
+		}
+	}
+	if strings.Contains(content, "switch fsm.state") {
+		t.Errorf("expected no nested switch:\n%s", content)
+	}
+}
+
+func TestGoTableStrategyRuns(t *testing.T) {
+	goTool := lookPath(t, "go")
+	directory := t.TempDir()
+	artifacts := generate(t, twoCoinTurnstile, "go", map[string]string{"package": "turnstile", "strategy": "table"})
+	artifacts[0].Name = "turnstile/" + artifacts[0].Name
+	events := `"` + strings.Join(twoCoinTurnstileEvents, `", "`) + `"`
+	artifacts = append(artifacts,
+		Artifact{Name: "go.mod", Content: "module example.com/generated\n\ngo 1.16\n"},
+		Artifact{Name: "main.go", Content: strings.Replace(goTurnstileMain, "%s", events, 1)},
+	)
+	writeArtifacts(t, directory, artifacts)
+
+	output := run(t, directory, goTool, "run", ".")
+	if output != twoCoinTurnstileTrace {
+		t.Fatalf("expected\n%s\nbut got\n%s", twoCoinTurnstileTrace, output)
+	}
+}
+
+func TestJavaTableStrategy(t *testing.T) {
+	artifacts := generate(t, twoCoinTurnstile, "java", map[string]string{"package": "turnstile", "strategy": "table"})
+	if len(artifacts) != 1 || artifacts[0].Name != "turnstile/TwoCoinTurnstile.java" {
+		t.Fatalf("unexpected artifacts %v", artifacts)
+	}
+	content := artifacts[0].Content
+	for _, expected := range []string{
+		"package turnstile;\npublic abstract class TwoCoinTurnstile implements Turnstile {\n",
+		"private static final int[][] NEXT_STATES = {\n{-1, -1, 2},\n",
+		"private static final int[][] ACTION_LISTS = {\n{},\n{0, 2},\n",
+		"int[] actionList = ACTION_LISTS[ACTION_LIST_INDICES[state.ordinal()][event.ordinal()]];\n",
+		"setState(State.values()[next]);\n",
+		"case 0:\nalarmOff();\nbreak;\n",
+	} {
+		if !strings.Contains(content, expected) {
+			t.Errorf("expected generated code to contain %q:\n%s", expected, content)
+		}
+	}
+}
+
+func TestTableStrategyWithoutActionsHeader(t *testing.T) {
+	content := generate(t, "FSM: Door\nInitial: closed\n{\nclosed open closed ring\n}\n", "java",
+		map[string]string{"strategy": "table"})[0].Content
+	for _, expected := range []string{"public abstract class Door {\n", "protected abstract void ring();\n"} {
+		if !strings.Contains(content, expected) {
+			t.Errorf("expected generated code to contain %q:\n%s", expected, content)
+		}
+	}
+}
+
+func TestJavaRejectsCollidingMembers(t *testing.T) {
+	for name, test := range map[string]struct {
+		strategy, source, message string
+	}{
+		"event and action": {"switch", "FSM: f\nInitial: a\n{\n  a lock a lock\n}\n",
+			"event lock and action lock of f both become the method lock"},
+		"table event and action": {"table", "FSM: f\nInitial: a\n{\n  a lock a lock\n}\n",
+			"event lock and action lock of f both become the method lock"},
+		"event and fixed member": {"switch", "FSM: f\nInitial: a\n{\n  a handleEvent a {}\n}\n",
+			"event handleEvent of f collides with the handleEvent member of the generated class"},
+		"table action and fixed member": {"table", "FSM: f\nInitial: a\n{\n  a e a executeAction\n}\n",
+			"action executeAction of f collides with the executeAction member of the generated class"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := generateError(t, test.source, "java", map[string]string{"strategy": test.strategy})
+			if err == nil || err.Error() != test.message {
+				t.Fatalf("expected %q, got %v", test.message, err)
+			}
+		})
+	}
+	// Only the table strategy declares executeAction.
+	if err := generateError(t, "FSM: f\nInitial: a\n{\n  a e a executeAction\n}\n", "java", map[string]string{}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestUnsupportedStrategies(t *testing.T) {
+	if _, err := NewGenerator("python", map[string]string{"strategy": "table"}); err == nil {
+		t.Error("expected an error for a language without strategy=table")
+	}
+	if _, err := NewGenerator("go", map[string]string{"strategy": "jump"}); err == nil {
+		t.Error("expected an error for an unknown strategy")
+	}
+}
@@ -0,0 +1,157 @@
+package generator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/larkvincer/dsl-fsm/generator/implementors"
+	nscgenerator "github.com/larkvincer/dsl-fsm/generator/nestedswitchcasegenerator"
+	"github.com/larkvincer/dsl-fsm/optimizer"
+)
+
+const templateExtension = ".tmpl"
+
+// TemplateGenerator renders the *.tmpl files of the directory given by the
+// templates flag with text/template. Every file becomes one artifact, named
+// like the file without the extension; the name is a template itself, so
+// "{{snake .Header.Fsm}}.py.tmpl" names the artifact after the FSM. Files
+// starting with an underscore only hold {{define}}d templates for the
+// others. Templates see a TemplateData and the functions in templateFuncs,
+// plus include, which renders a named template into a string.
+type TemplateGenerator struct {
+	flags     map[string]string
+	templates *template.Template
+	names     []string
+}
+
+// TemplateData is what templates render: the optimized state machine, its
+// nested switch/case tree and the generator flags.
+type TemplateData struct {
+	*optimizer.OptimizedStateMachine
+	Tree  *nscgenerator.FSMClassNode
+	Flags map[string]string
+}
+
+var templateFuncs = template.FuncMap{
+	"snake":      implementors.SnakeCase,
+	"upperSnake": func(name string) string { return strings.ToUpper(implementors.SnakeCase(name)) },
+	"kebab":      func(name string) string { return strings.ReplaceAll(implementors.SnakeCase(name), "_", "-") },
+	"camel":      camelCase,
+	"pascal":     pascalCase,
+	"lower":      strings.ToLower,
+	"upper":      strings.ToUpper,
+	"join":       func(separator string, items []string) string { return strings.Join(items, separator) },
+	"indent":     indentLines,
+}
+
+func NewTemplateGenerator(flags map[string]string) (*TemplateGenerator, error) {
+	directory := flags["templates"]
+	if directory == "" {
+		return nil, fmt.Errorf("the template generator needs a templates directory, e.g. -templates dir")
+	}
+	paths, err := filepath.Glob(filepath.Join(directory, "*"+templateExtension))
+	if err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no %s files in %s", templateExtension, directory)
+	}
+	sort.Strings(paths)
+
+	templateGenerator := &TemplateGenerator{flags: flags}
+	templateGenerator.templates = template.New("").Funcs(templateFuncs).Funcs(template.FuncMap{
+		"include": templateGenerator.include,
+	})
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		name := filepath.Base(path)
+		if _, err := templateGenerator.templates.New(name).Parse(string(content)); err != nil {
+			return nil, err
+		}
+		if !strings.HasPrefix(name, "_") {
+			templateGenerator.names = append(templateGenerator.names, name)
+		}
+	}
+	return templateGenerator, nil
+}
+
+func (templateGenerator *TemplateGenerator) Generate(machine *StateMachine) ([]Artifact, error) {
+	nscGenerator := nscgenerator.NSCGenerator{}
+	data := &TemplateData{
+		OptimizedStateMachine: machine.Optimized,
+		Tree:                  nscGenerator.Generate(machine.Optimized),
+		Flags:                 templateGenerator.flags,
+	}
+
+	artifacts := []Artifact{}
+	for _, name := range templateGenerator.names {
+		artifactName, err := templateGenerator.renderName(strings.TrimSuffix(name, templateExtension), data)
+		if err != nil {
+			return nil, err
+		}
+		content := &strings.Builder{}
+		if err := templateGenerator.templates.ExecuteTemplate(content, name, data); err != nil {
+			return nil, err
+		}
+		artifacts = append(artifacts, Artifact{Name: artifactName, Content: content.String()})
+	}
+	return artifacts, nil
+}
+
+// include renders a named template into a string, so that its output can be
+// piped into other functions: {{include "body" . | indent 4}}.
+func (templateGenerator *TemplateGenerator) include(name string, data interface{}) (string, error) {
+	content := &strings.Builder{}
+	err := templateGenerator.templates.ExecuteTemplate(content, name, data)
+	return content.String(), err
+}
+
+func (templateGenerator *TemplateGenerator) renderName(name string, data *TemplateData) (string, error) {
+	nameTemplate, err := template.New(name).Funcs(templateFuncs).Parse(name)
+	if err != nil {
+		return "", err
+	}
+	rendered := &strings.Builder{}
+	if err := nameTemplate.Execute(rendered, data); err != nil {
+		return "", err
+	}
+	return rendered.String(), nil
+}
+
+func pascalCase(name string) string {
+	words := strings.Split(implementors.SnakeCase(name), "_")
+	for i, word := range words {
+		if word != "" {
+			words[i] = strings.ToUpper(word[:1]) + word[1:]
+		}
+	}
+	return strings.Join(words, "")
+}
+
+func camelCase(name string) string {
+	pascal := pascalCase(name)
+	if pascal == "" {
+		return pascal
+	}
+	return strings.ToLower(pascal[:1]) + pascal[1:]
+}
+
+// indentLines prefixes every non-empty line of text with the given number
+// of spaces.
+func indentLines(spaces int, text string) string {
+	prefix := strings.Repeat(" ", spaces)
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if strings.TrimSpace(line) != "" {
+			lines[i] = prefix + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,94 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/larkvincer/dsl-fsm/optimizer"
+)
+
+func writeTemplates(t *testing.T, templates map[string]string) string {
+	t.Helper()
+	directory := t.TempDir()
+	for name, content := range templates {
+		if err := os.WriteFile(filepath.Join(directory, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return directory
+}
+
+func TestTemplateGenerator(t *testing.T) {
+	directory := writeTemplates(t, map[string]string{
+		"{{snake .Header.Fsm}}.txt.tmpl": "" +
+			"{{.Header.Fsm}} starts in {{.Header.Initial}} ({{.Flags.owner}})\n" +
+			"states: {{join \", \" .States}}\n" +
+			"{{range .Transitions}}{{include \"state\" . | indent 2}}{{end}}",
+		"_state.tmpl": "" +
+			"{{define \"state\"}}{{upperSnake .CurrentState}}:\n" +
+			"{{range .SubTransitions}}  {{camel .Event}} -> {{pascal .NextState}} [{{join \" \" .Actions}}]\n{{end}}{{end}}",
+		"tree.txt.tmpl": "" +
+			"class {{.Tree.ClassName}}: {{join \" \" .Tree.StateEnum.Enumerators}}\n" +
+			"{{range .Tree.HandleEvent.SwitchCase.CaseNodes}}{{.CaseName}} {{end}}\n",
+		"notes.md": "not a template\n",
+	})
+	artifacts := generate(t, twoCoinTurnstile, "template", map[string]string{"templates": directory, "owner": "ops"})
+	if len(artifacts) != 2 || artifacts[0].Name != "tree.txt" || artifacts[1].Name != "two_coin_turnstile.txt" {
+		t.Fatalf("unexpected artifacts %v", artifacts)
+	}
+	if expected := "class TwoCoinTurnstile: Alarming FirstCoin Locked Unlocked\nAlarming FirstCoin Locked Unlocked \n"; artifacts[0].Content != expected {
+		t.Errorf("expected %q, got %q", expected, artifacts[0].Content)
+	}
+	for _, expected := range []string{
+		"TwoCoinTurnstile starts in Locked (ops)\n",
+		"states: Alarming, FirstCoin, Locked, Unlocked\n",
+		"  ALARMING:\n    reset -> Locked [alarmOff lock]\n",
+		"  UNLOCKED:\n    pass -> Locked [lock]\n    coin -> Unlocked [thankyou]\n",
+	} {
+		if !strings.Contains(artifacts[1].Content, expected) {
+			t.Errorf("expected rendered template to contain %q:\n%s", expected, artifacts[1].Content)
+		}
+	}
+}
+
+func TestTemplateCaseConversions(t *testing.T) {
+	for _, test := range []struct{ function, input, expected string }{
+		{"snake", "alarmOn", "alarm_on"},
+		{"upperSnake", "FirstCoin", "FIRST_COIN"},
+		{"kebab", "HTTPRequest", "http-request"},
+		{"camel", "FirstCoin", "firstCoin"},
+		{"pascal", "alarm_on", "AlarmOn"},
+	} {
+		if result := templateFuncs[test.function].(func(string) string)(test.input); result != test.expected {
+			t.Errorf("%s(%q): expected %q, got %q", test.function, test.input, test.expected, result)
+		}
+	}
+	if result := indentLines(2, "a\n\nb"); result != "  a\n\n  b" {
+		t.Errorf("unexpected indentation %q", result)
+	}
+}
+
+func TestTemplateGeneratorErrors(t *testing.T) {
+	if _, err := NewGenerator("template", map[string]string{}); err == nil {
+		t.Error("expected an error without a templates directory")
+	}
+	if _, err := NewGenerator("template", map[string]string{"templates": t.TempDir()}); err == nil {
+		t.Error("expected an error for a directory without templates")
+	}
+	directory := writeTemplates(t, map[string]string{"broken.tmpl": "{{.Header.Fsm"})
+	if _, err := NewGenerator("template", map[string]string{"templates": directory}); err == nil {
+		t.Error("expected an error for a template that does not parse")
+	}
+
+	directory = writeTemplates(t, map[string]string{"fails.tmpl": "{{.Header.Missing}}"})
+	codeGenerator, err := NewGenerator("template", map[string]string{"templates": directory})
+	if err != nil {
+		t.Fatal(err)
+	}
+	semanticStateMachine := analyze(t, twoCoinTurnstile)
+	if _, err := codeGenerator.Generate(&StateMachine{Semantic: semanticStateMachine, Optimized: optimizer.Optimize(*semanticStateMachine)}); err == nil {
+		t.Error("expected an error for a template that fails to execute")
+	}
+}
@@ -0,0 +1,102 @@
+package ttgenerator
+
+import (
+	"fmt"
+
+	"github.com/larkvincer/dsl-fsm/optimizer"
+)
+
+// Unhandled marks a (state, event) cell of NextStates without a transition.
+const Unhandled = -1
+
+// TransitionTable is the optimized state machine flattened into arrays that
+// are indexed by state and event ordinals, i.e. their positions in States
+// and Events. Every distinct list of actions is stored once in ActionLists,
+// as positions in Actions; ActionListIndices points each cell at its list.
+// ActionLists[0] is always the empty list.
+type TransitionTable struct {
+	ClassName         string
+	ActionsName       string
+	States            []string
+	Events            []string
+	Actions           []string
+	InitialState      int
+	NextStates        [][]int
+	ActionListIndices [][]int
+	ActionLists       [][]int
+}
+
+type TransitionTableImplementor interface {
+	Implement(table *TransitionTable)
+}
+
+type TTGenerator struct {
+	stateOrdinals  map[string]int
+	eventOrdinals  map[string]int
+	actionOrdinals map[string]int
+	actionLists    map[string]int
+}
+
+// Generate fails when the initial state is not one of the concrete states
+// in osm.States, e.g. an abstract state, as the table has no row for it.
+func (ttg *TTGenerator) Generate(osm *optimizer.OptimizedStateMachine) (*TransitionTable, error) {
+	ttg.stateOrdinals = ordinals(osm.States)
+	initialState, ok := ttg.stateOrdinals[osm.Header.Initial]
+	if !ok {
+		return nil, fmt.Errorf("initial state %s of %s is not a concrete state", osm.Header.Initial, osm.Header.Fsm)
+	}
+	ttg.eventOrdinals = ordinals(osm.Events)
+	ttg.actionOrdinals = ordinals(osm.Actions)
+	ttg.actionLists = map[string]int{"": 0}
+
+	table := &TransitionTable{
+		ClassName:    osm.Header.Fsm,
+		ActionsName:  osm.Header.Actions,
+		States:       osm.States,
+		Events:       osm.Events,
+		Actions:      osm.Actions,
+		InitialState: initialState,
+		ActionLists:  [][]int{{}},
+	}
+	for range osm.States {
+		nextStates := make([]int, len(osm.Events))
+		for event := range nextStates {
+			nextStates[event] = Unhandled
+		}
+		table.NextStates = append(table.NextStates, nextStates)
+		table.ActionListIndices = append(table.ActionListIndices, make([]int, len(osm.Events)))
+	}
+	for _, transition := range osm.Transitions {
+		state := ttg.stateOrdinals[transition.CurrentState]
+		for _, subTransition := range transition.SubTransitions {
+			event := ttg.eventOrdinals[subTransition.Event]
+			table.NextStates[state][event] = ttg.stateOrdinals[subTransition.NextState]
+			table.ActionListIndices[state][event] = ttg.addActionList(table, subTransition.Actions)
+		}
+	}
+	return table, nil
+}
+
+func (ttg *TTGenerator) addActionList(table *TransitionTable, actions []string) int {
+	key := ""
+	actionList := []int{}
+	for _, action := range actions {
+		key += action + "\n"
+		actionList = append(actionList, ttg.actionOrdinals[action])
+	}
+	if index, ok := ttg.actionLists[key]; ok {
+		return index
+	}
+	index := len(table.ActionLists)
+	ttg.actionLists[key] = index
+	table.ActionLists = append(table.ActionLists, actionList)
+	return index
+}
+
+func ordinals(names []string) map[string]int {
+	ordinals := make(map[string]int, len(names))
+	for ordinal, name := range names {
+		ordinals[name] = ordinal
+	}
+	return ordinals
+}
@@ -0,0 +1,39 @@
+package generator
+
+import (
+	"github.com/larkvincer/dsl-fsm/generator/implementors"
+	nscgenerator "github.com/larkvincer/dsl-fsm/generator/nestedswitchcasegenerator"
+)
+
+type TypeScriptCodeGenerator struct {
+	typeScriptNestedSwitchCaseImplementor *implementors.TypeScriptNestedSwitchCaseImplementor
+}
+
+func NewTypeScriptCodeGenerator(
+	typeScriptNestedSwitchCaseImplementor *implementors.TypeScriptNestedSwitchCaseImplementor,
+) *TypeScriptCodeGenerator {
+	return &TypeScriptCodeGenerator{
+		typeScriptNestedSwitchCaseImplementor: typeScriptNestedSwitchCaseImplementor,
+	}
+}
+
+func (tsGenerator *TypeScriptCodeGenerator) GetImplementer() nscgenerator.NSCNodeVisitor {
+	return tsGenerator.typeScriptNestedSwitchCaseImplementor
+}
+
+func (tsGenerator *TypeScriptCodeGenerator) GetArtifacts(fsmName string) []Artifact {
+	return []Artifact{{
+		Name:    fsmName + ".ts",
+		Content: indentBlocks(tsGenerator.typeScriptNestedSwitchCaseImplementor.Output, "    "),
+	}}
+}
+
+func (tsGenerator *TypeScriptCodeGenerator) MemberName(name string) string {
+	return name
+}
+
+// FixedMembers are the members of the generated class besides the events
+// and actions.
+func (tsGenerator *TypeScriptCodeGenerator) FixedMembers(className string) map[string]bool {
+	return setOf("constructor", "state", "getState", "setState", "handleEvent", "unhandledTransition")
+}
@@ -0,0 +1,78 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTypeScriptArtifact(t *testing.T) {
+	artifacts := generate(t, twoCoinTurnstile, "typescript", map[string]string{})
+	if len(artifacts) != 1 || artifacts[0].Name != "TwoCoinTurnstile.ts" {
+		t.Fatalf("unexpected artifacts %v", artifacts)
+	}
+	content := artifacts[0].Content
+	for _, expected := range []string{
+		"export enum State {\n    Alarming = \"Alarming\",\n",
+		"export interface Turnstile {\n    alarmOff(): void;\n",
+		"export abstract class TwoCoinTurnstile implements Turnstile {\n",
+		"    Coin(): void {\n        this.handleEvent(Event.Coin);\n    }\n",
+		"    private handleEvent(event: Event): void {\n        switch (this.state) {\n",
+		"    abstract unhandledTransition(state: string, event: string): void;\n",
+	} {
+		if !strings.Contains(content, expected) {
+			t.Errorf("expected generated code to contain %q:\n%s", expected, content)
+		}
+	}
+	if strings.Contains(content, "namespace") {
+		t.Errorf("expected an ES module:\n%s", content)
+	}
+}
+
+func TestTypeScriptCommonJS(t *testing.T) {
+	artifacts := generate(t, twoCoinTurnstile, "typescript", map[string]string{"module": "commonjs"})
+	content := artifacts[0].Content
+	if !strings.Contains(content, "namespace TwoCoinTurnstileModule {\n    export enum State {\n") ||
+		!strings.HasSuffix(content, "}\n\nexport = TwoCoinTurnstileModule;\n") {
+		t.Fatalf("expected a CommonJS module:\n%s", content)
+	}
+}
+
+func TestTypeScriptActionsWithoutActionsInterface(t *testing.T) {
+	artifacts := generate(t, "FSM: f\nInitial: i\n{\ni e i a\n}\n", "typescript", map[string]string{})
+	content := artifacts[0].Content
+	if !strings.Contains(content, "export abstract class f {\n") ||
+		!strings.Contains(content, "    protected abstract a(): void;\n") {
+		t.Fatalf("expected protected abstract actions:\n%s", content)
+	}
+}
+
+func TestTypeScriptRejectsCollidingMembers(t *testing.T) {
+	for name, test := range map[string]struct{ source, message string }{
+		"event and action": {"FSM: f\nInitial: a\n{\n  a lock a lock\n}\n",
+			"event lock and action lock of f both become the method lock"},
+		"event and fixed member": {"FSM: f\nInitial: a\n{\n  a getState a {}\n}\n",
+			"event getState of f collides with the getState member of the generated class"},
+		"action and state property": {"FSM: f\nInitial: a\n{\n  a e a state\n}\n",
+			"action state of f collides with the state member of the generated class"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := generateError(t, test.source, "typescript", map[string]string{})
+			if err == nil || err.Error() != test.message {
+				t.Fatalf("expected %q, got %v", test.message, err)
+			}
+		})
+	}
+	// Unlike C#, TypeScript keeps the case of the names.
+	if err := generateError(t, "FSM: f\nInitial: a\n{\n  a lock a Lock\n}\n", "typescript", map[string]string{}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTypeScriptArtifactTypeChecks(t *testing.T) {
+	tsc := lookPath(t, "tsc")
+	for _, module := range []string{"esm", "commonjs"} {
+		directory := t.TempDir()
+		writeArtifacts(t, directory, generate(t, twoCoinTurnstile, "typescript", map[string]string{"module": module}))
+		run(t, directory, tsc, "--strict", "--noEmit", "--module", "commonjs", "TwoCoinTurnstile.ts")
+	}
+}
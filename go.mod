@@ -1,3 +1,5 @@
 module github.com/larkvincer/dsl-fsm
 
 go 1.16
+
+require github.com/mattn/go-sqlite3 v1.14.33
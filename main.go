@@ -1,47 +1,223 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
 
+	"github.com/larkvincer/dsl-fsm/compiler"
+	"github.com/larkvincer/dsl-fsm/diagnostics"
 	"github.com/larkvincer/dsl-fsm/generator"
-	"github.com/larkvincer/dsl-fsm/generator/implementors"
-	"github.com/larkvincer/dsl-fsm/lexer"
-	"github.com/larkvincer/dsl-fsm/optimizer"
-	"github.com/larkvincer/dsl-fsm/parser"
-	"github.com/larkvincer/dsl-fsm/semanticanalyzer"
 )
 
+const stdinName = "<stdin>"
+
+type generatorFlags map[string]string
+
+func (flags generatorFlags) String() string {
+	pairs := []string{}
+	for key, value := range flags {
+		pairs = append(pairs, key+"="+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+func (flags generatorFlags) Set(pair string) error {
+	keyValue := strings.SplitN(pair, "=", 2)
+	if len(keyValue) != 2 || keyValue[0] == "" {
+		return fmt.Errorf("generator flag %q is not in key=value form", pair)
+	}
+	flags[keyValue[0]] = keyValue[1]
+	return nil
+}
+
+var diagnosticsWriters = map[string]func(stdout, stderr io.Writer, all []diagnostics.Diagnostic) error{
+	"text": func(stdout, stderr io.Writer, all []diagnostics.Diagnostic) error {
+		for _, diagnostic := range all {
+			if _, err := fmt.Fprintln(stderr, diagnostic); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+	"json": func(stdout, stderr io.Writer, all []diagnostics.Diagnostic) error {
+		return diagnostics.WriteJSON(stdout, all)
+	},
+	"sarif": func(stdout, stderr io.Writer, all []diagnostics.Diagnostic) error {
+		return diagnostics.WriteSARIF(stdout, "smc", all)
+	},
+}
+
+type smcCompiler struct {
+	language          string
+	outputDirectory   string
+	flags             generatorFlags
+	diagnosticsFormat string
+	diagnostics       []diagnostics.Diagnostic
+	archive           string
+	check             bool
+	templates         string
+	sink              generator.OutputSink
+	stderr            io.Writer
+}
+
 func main() {
-	const sourceInput = `Initial: Locked
-	Actions: Turnstile
-	FSM: TurnstileFSM
-	{
-	  Locked    {
-	    Coin    Unlocked    unlock
-	    Pass    Locked      alarm
-	  }
-	  Unlocked  {
-	    Coin    Unlocked    thankyou
-	    Pass    Locked      lock
-	  }
-	}`
-	syntaxBuilder := parser.NewFsmSyntaxBuilder()
-	parser := parser.NewParser(syntaxBuilder)
-	lexer := lexer.New(parser)
-	lexer.Lex(sourceInput)
-	parser.HandleEvent("EOF", -1, -1)
-	fsm := syntaxBuilder.GetFSM()
-
-	if len(fsm.Errors) == 0 {
-		semanticStateMachine := semanticanalyzer.New().Analyze(fsm)
-		optimizedStateMachine := optimizer.Optimize(*semanticStateMachine)
-		flags := make(map[string]string)
-		flags["package"] = "firsttry"
-		javaImplementor := implementors.NewJavaNestedSwitchCaseImplementor(flags)
-		javaCodeGenerator := generator.NewJavaCodeGenerator(javaImplementor)
-		codeGenerator := generator.NewCodeGenerator(optimizedStateMachine, javaCodeGenerator)
-		codeGenerator.Generate()
-	} else {
-		fmt.Print(fsm.Errors)
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	smc := &smcCompiler{flags: generatorFlags{}, stderr: stderr}
+	commandLine := flag.NewFlagSet("smc", flag.ContinueOnError)
+	commandLine.SetOutput(stderr)
+	commandLine.Usage = func() {
+		fmt.Fprintln(stderr, "usage: smc [-g generator | -templates directory] [-o directory | -archive file.zip] [-check] [-f key=value]... [-diagnostics format] [file.sm | file.scxml]...")
+		commandLine.PrintDefaults()
+	}
+	commandLine.StringVar(&smc.language, "g", "java", "code generator, one of: "+strings.Join(generator.Languages(), ", "))
+	commandLine.StringVar(&smc.outputDirectory, "o", ".", "directory generated files are written to")
+	commandLine.Var(smc.flags, "f", "generator flag as key=value, e.g. package=firsttry (repeatable)")
+	commandLine.StringVar(
+		&smc.diagnosticsFormat, "diagnostics", "text",
+		"diagnostics format: text (to stderr), json or sarif (to stdout)",
+	)
+	commandLine.StringVar(&smc.archive, "archive", "", "write generated files into this zip archive instead of the output directory")
+	commandLine.BoolVar(&smc.check, "check", false, "write nothing and fail if generated files in the output directory are out of date")
+	commandLine.StringVar(&smc.templates, "templates", "", "directory of text/template files to render, implies -g template")
+	if err := commandLine.Parse(args); err != nil {
+		return 2
+	}
+	smc.useTemplates(commandLine)
+	if smc.check && smc.archive != "" {
+		fmt.Fprintln(stderr, "smc: -check and -archive cannot be combined")
+		return 2
+	}
+	if _, err := generator.NewGenerator(smc.language, smc.flags); err != nil {
+		fmt.Fprintf(stderr, "smc: %v\n", err)
+		return 2
+	}
+	writeDiagnostics, ok := diagnosticsWriters[smc.diagnosticsFormat]
+	if !ok {
+		fmt.Fprintf(stderr, "smc: unknown diagnostics format %q\n", smc.diagnosticsFormat)
+		return 2
+	}
+
+	if err := smc.openSink(); err != nil {
+		fmt.Fprintf(stderr, "smc: %v\n", err)
+		return 1
+	}
+
+	succeeded := true
+	if commandLine.NArg() == 0 {
+		succeeded = smc.compileReader(stdinName, stdin)
+	}
+	for _, fileName := range commandLine.Args() {
+		succeeded = smc.compileFile(fileName) && succeeded
+	}
+
+	if err := smc.sink.Close(); err != nil {
+		fmt.Fprintf(stderr, "smc: %v\n", err)
+		succeeded = false
+	}
+	if checkSink, ok := smc.sink.(*generator.CheckSink); ok {
+		for _, staleFile := range checkSink.GetStaleFiles() {
+			fmt.Fprintf(stderr, "smc: %s is out of date\n", staleFile)
+			succeeded = false
+		}
+	}
+	if err := writeDiagnostics(stdout, stderr, smc.diagnostics); err != nil {
+		fmt.Fprintf(stderr, "smc: %v\n", err)
+		return 1
+	}
+	return exitCode(succeeded)
+}
+
+// useTemplates passes -templates on to the template generator, which it
+// selects unless -g names another generator.
+func (smc *smcCompiler) useTemplates(commandLine *flag.FlagSet) {
+	if smc.templates == "" {
+		return
+	}
+	smc.flags["templates"] = smc.templates
+	languageSet := false
+	commandLine.Visit(func(f *flag.Flag) {
+		languageSet = languageSet || f.Name == "g"
+	})
+	if !languageSet {
+		smc.language = "template"
+	}
+}
+
+func (smc *smcCompiler) openSink() error {
+	switch {
+	case smc.check:
+		smc.sink = generator.NewCheckSink(smc.outputDirectory)
+	case smc.archive != "":
+		archive, err := os.Create(smc.archive)
+		if err != nil {
+			return err
+		}
+		smc.sink = &closingSink{OutputSink: generator.NewArchiveSink(archive), file: archive}
+	default:
+		smc.sink = generator.NewDiskSink(smc.outputDirectory)
+	}
+	return nil
+}
+
+type closingSink struct {
+	generator.OutputSink
+	file *os.File
+}
+
+func (sink *closingSink) Close() error {
+	if err := sink.OutputSink.Close(); err != nil {
+		sink.file.Close()
+		return err
+	}
+	return sink.file.Close()
+}
+
+func exitCode(succeeded bool) int {
+	if succeeded {
+		return 0
+	}
+	return 1
+}
+
+func (smc *smcCompiler) compileFile(fileName string) bool {
+	file, err := os.Open(fileName)
+	if err != nil {
+		fmt.Fprintf(smc.stderr, "smc: %v\n", err)
+		return false
+	}
+	defer file.Close()
+	return smc.compileReader(fileName, file)
+}
+
+func (smc *smcCompiler) compileReader(sourceName string, reader io.Reader) bool {
+	result, err := compiler.Compile(
+		reader,
+		compiler.Options{
+			FileName: sourceName,
+			Format:   compiler.FormatOf(sourceName),
+			Language: smc.language,
+			Flags:    smc.flags,
+		},
+	)
+	smc.diagnostics = append(smc.diagnostics, result.Diagnostics...)
+	if err != nil {
+		if !errors.Is(err, compiler.ErrCompilationFailed) {
+			fmt.Fprintf(smc.stderr, "smc: %s: %v\n", sourceName, err)
+		}
+		return false
+	}
+	if err := generator.WriteArtifacts(smc.sink, result.Artifacts); err != nil {
+		fmt.Fprintf(smc.stderr, "smc: %s: %v\n", sourceName, err)
+		return false
 	}
+	return true
 }
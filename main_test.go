@@ -0,0 +1,188 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const turnstile = "" +
+	"FSM: Turnstile\n" +
+	"Initial: Locked\n" +
+	"{\n" +
+	"  Locked Coin Unlocked unlock\n" +
+	"  Unlocked Pass Locked lock\n" +
+	"}\n"
+
+// runSmc runs the command line with the given stdin and returns its exit
+// code, stdout and stderr.
+func runSmc(t *testing.T, stdin string, args ...string) (int, string, string) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	code := run(args, strings.NewReader(stdin), &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestRunCompilesStdin(t *testing.T) {
+	directory := t.TempDir()
+	code, _, stderr := runSmc(t, turnstile, "-g", "dot", "-o", directory)
+	if code != 0 {
+		t.Fatalf("expected exit code 0, got %d: %s", code, stderr)
+	}
+	content, err := os.ReadFile(filepath.Join(directory, "turnstile.dot"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), "\"Locked\" -> \"Unlocked\" [label=\"Coin / unlock\"];") {
+		t.Errorf("unexpected graph:\n%s", content)
+	}
+}
+
+func TestRunCompilesFiles(t *testing.T) {
+	directory := t.TempDir()
+	source := filepath.Join(directory, "turnstile.sm")
+	if err := os.WriteFile(source, []byte(turnstile), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if code, _, stderr := runSmc(t, "", "-g", "dot", "-o", directory, source); code != 0 {
+		t.Fatalf("expected exit code 0, got %d: %s", code, stderr)
+	}
+	if _, err := os.Stat(filepath.Join(directory, "turnstile.dot")); err != nil {
+		t.Fatal(err)
+	}
+
+	code, _, stderr := runSmc(t, "", "-g", "dot", "-o", directory, filepath.Join(directory, "missing.sm"))
+	if code != 1 || !strings.Contains(stderr, "missing.sm") {
+		t.Fatalf("expected exit code 1 naming the missing file, got %d: %s", code, stderr)
+	}
+}
+
+func TestRunFailsOnCompilationErrors(t *testing.T) {
+	directory := t.TempDir()
+	code, _, stderr := runSmc(t, "FSM: f\nInitial: i\n{\n  i e undefined {}\n}\n", "-g", "dot", "-o", directory)
+	if code != 1 {
+		t.Fatalf("expected exit code 1, got %d: %s", code, stderr)
+	}
+	if !strings.Contains(stderr, "<stdin>:4:") || !strings.Contains(stderr, "UNDEFINED_STATE") {
+		t.Errorf("expected a located diagnostic for <stdin>, got %s", stderr)
+	}
+	if entries, _ := os.ReadDir(directory); len(entries) != 0 {
+		t.Errorf("expected nothing to be written, got %v", entries)
+	}
+}
+
+func TestRunWritesJSONDiagnosticsToStdout(t *testing.T) {
+	code, stdout, stderr := runSmc(t, "FSM: f\nInitial: i\n{\n  i e undefined {}\n}\n",
+		"-g", "dot", "-o", t.TempDir(), "-diagnostics", "json")
+	if code != 1 || stderr != "" || !strings.Contains(stdout, "\"UNDEFINED_STATE\"") {
+		t.Fatalf("expected exit code 1 and JSON on stdout, got %d:\n%s\n%s", code, stdout, stderr)
+	}
+}
+
+func TestRunRejectsUsageErrors(t *testing.T) {
+	for name, test := range map[string]struct {
+		args    []string
+		message string
+	}{
+		"unknown flag":               {[]string{"-bogus"}, "flag provided but not defined: -bogus"},
+		"unknown generator":          {[]string{"-g", "cobol"}, "smc: "},
+		"unknown diagnostics format": {[]string{"-g", "dot", "-diagnostics", "xml"}, "unknown diagnostics format \"xml\""},
+		"malformed generator flag":   {[]string{"-f", "package"}, "generator flag \"package\" is not in key=value form"},
+		"check with archive": {
+			[]string{"-g", "dot", "-check", "-archive", "out.zip"},
+			"smc: -check and -archive cannot be combined",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			code, _, stderr := runSmc(t, turnstile, test.args...)
+			if code != 2 {
+				t.Fatalf("expected exit code 2, got %d: %s", code, stderr)
+			}
+			if !strings.Contains(stderr, test.message) {
+				t.Errorf("expected stderr to contain %q, got %s", test.message, stderr)
+			}
+		})
+	}
+}
+
+func TestRunChecksOutputDirectory(t *testing.T) {
+	directory := t.TempDir()
+	if code, _, stderr := runSmc(t, turnstile, "-g", "dot", "-o", directory); code != 0 {
+		t.Fatalf("expected exit code 0, got %d: %s", code, stderr)
+	}
+	if code, _, stderr := runSmc(t, turnstile, "-g", "dot", "-o", directory, "-check"); code != 0 {
+		t.Fatalf("expected an up to date directory to pass -check, got %d: %s", code, stderr)
+	}
+
+	if err := os.WriteFile(filepath.Join(directory, "turnstile.dot"), []byte("edited"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	code, _, stderr := runSmc(t, turnstile, "-g", "dot", "-o", directory, "-check")
+	if code != 1 || !strings.Contains(stderr, "turnstile.dot is out of date") {
+		t.Fatalf("expected exit code 1 naming the stale file, got %d: %s", code, stderr)
+	}
+	if content, _ := os.ReadFile(filepath.Join(directory, "turnstile.dot")); string(content) != "edited" {
+		t.Errorf("expected -check to write nothing, got %s", content)
+	}
+}
+
+func TestRunWritesArchive(t *testing.T) {
+	archive := filepath.Join(t.TempDir(), "out.zip")
+	if code, _, stderr := runSmc(t, turnstile, "-g", "dot", "-archive", archive); code != 0 {
+		t.Fatalf("expected exit code 0, got %d: %s", code, stderr)
+	}
+	reader, err := zip.OpenReader(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+	if len(reader.File) != 1 || reader.File[0].Name != "turnstile.dot" {
+		t.Fatalf("unexpected archive entries %v", reader.File)
+	}
+}
+
+func TestRunRendersTemplates(t *testing.T) {
+	templates := t.TempDir()
+	if err := os.WriteFile(filepath.Join(templates, "states.txt.tmpl"), []byte("{{join \" \" .States}}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	directory := t.TempDir()
+	if code, _, stderr := runSmc(t, turnstile, "-templates", templates, "-o", directory); code != 0 {
+		t.Fatalf("expected exit code 0, got %d: %s", code, stderr)
+	}
+	if content, _ := os.ReadFile(filepath.Join(directory, "states.txt")); string(content) != "Locked Unlocked\n" {
+		t.Errorf("expected the template to be rendered, got %q", content)
+	}
+}
+
+func TestUseTemplates(t *testing.T) {
+	for name, test := range map[string]struct {
+		args     []string
+		language string
+	}{
+		"implies the template generator": {[]string{"-templates", "dir"}, "template"},
+		"keeps an explicit generator":    {[]string{"-g", "java", "-templates", "dir"}, "java"},
+		"without templates":              {[]string{}, "java"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			smc := &smcCompiler{flags: generatorFlags{}}
+			commandLine := flag.NewFlagSet("smc", flag.ContinueOnError)
+			commandLine.StringVar(&smc.language, "g", "java", "")
+			commandLine.StringVar(&smc.templates, "templates", "", "")
+			if err := commandLine.Parse(test.args); err != nil {
+				t.Fatal(err)
+			}
+			smc.useTemplates(commandLine)
+			if smc.language != test.language {
+				t.Errorf("expected generator %s, got %s", test.language, smc.language)
+			}
+			if smc.flags["templates"] != smc.templates {
+				t.Errorf("expected the templates flag %q, got %q", smc.templates, smc.flags["templates"])
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package optimizer
 
 import (
 	"reflect"
+	"sort"
 
 	"github.com/larkvincer/dsl-fsm/semanticanalyzer"
 )
@@ -38,17 +39,42 @@ func (optimizer *Optimizer) addLists() {
 }
 
 func (optimizer *Optimizer) addStates() {
-	for _, state := range optimizer.semanticStateMachine.States {
+	for _, state := range optimizer.sortedStates() {
 		if !state.AbstractState {
 			optimizer.optimizedStateMachine.States = append(optimizer.optimizedStateMachine.States, state.Name)
 		}
 	}
 }
+
+func (optimizer *Optimizer) sortedStates() []*semanticanalyzer.SemanticState {
+	return sortStates(optimizer.semanticStateMachine.States)
+}
+
+func sortStates(states map[string]*semanticanalyzer.SemanticState) []*semanticanalyzer.SemanticState {
+	sortedStates := []*semanticanalyzer.SemanticState{}
+	for _, state := range states {
+		sortedStates = append(sortedStates, state)
+	}
+	sort.Slice(sortedStates, func(i, j int) bool {
+		return sortedStates[i].Name < sortedStates[j].Name
+	})
+	return sortedStates
+}
+
+func sortedSuperStates(state *semanticanalyzer.SemanticState) []*semanticanalyzer.SemanticState {
+	superStates := make(map[string]*semanticanalyzer.SemanticState)
+	for superState := range state.SuperStates {
+		superStates[superState.Name] = superState
+	}
+	return sortStates(superStates)
+}
+
 func (optimizer *Optimizer) addEvents() {
 	events := []string{}
 	for event := range optimizer.semanticStateMachine.Events {
 		events = append(events, event)
 	}
+	sort.Strings(events)
 
 	optimizer.optimizedStateMachine.Events = append(
 		optimizer.optimizedStateMachine.Events,
@@ -61,6 +87,7 @@ func (optimizer *Optimizer) addActions() {
 	for action := range optimizer.semanticStateMachine.Actions {
 		actions = append(actions, action)
 	}
+	sort.Strings(actions)
 
 	optimizer.optimizedStateMachine.Actions = append(
 		optimizer.optimizedStateMachine.Actions,
@@ -69,7 +96,7 @@ func (optimizer *Optimizer) addActions() {
 }
 
 func (optimizer *Optimizer) addTransitions() {
-	for _, semanticState := range optimizer.semanticStateMachine.States {
+	for _, semanticState := range optimizer.sortedStates() {
 		if !semanticState.AbstractState {
 			NewStateOptimizer(optimizer, semanticState).addTransitionsForState()
 		}
@@ -81,7 +108,7 @@ func (optimizer *Optimizer) addAllStatesInHiearchyLeafFirst(
 	hierarchy []*semanticanalyzer.SemanticState,
 ) []*semanticanalyzer.SemanticState {
 	//Check this method, can be wrong
-	for superState := range semanticState.SuperStates {
+	for _, superState := range sortedSuperStates(semanticState) {
 		contains := false
 		for _, stateInHierarchy := range hierarchy {
 			if reflect.DeepEqual(superState, stateInHierarchy) {
@@ -96,6 +123,13 @@ func (optimizer *Optimizer) addAllStatesInHiearchyLeafFirst(
 	return append(hierarchy, semanticState)
 }
 
+// RootFirstHierarchy returns state preceded by all of its superstates in
+// the order SubTransitionOptimizer runs their entry actions; reversed, it is
+// the order their exit actions run in.
+func RootFirstHierarchy(state *semanticanalyzer.SemanticState) []*semanticanalyzer.SemanticState {
+	return (&Optimizer{}).addAllStatesInHiearchyLeafFirst(state, []*semanticanalyzer.SemanticState{})
+}
+
 type StateOptimizer struct {
 	optimizer          *Optimizer
 	currentState       *semanticanalyzer.SemanticState
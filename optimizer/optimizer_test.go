@@ -1,6 +1,7 @@
 package optimizer
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/larkvincer/dsl-fsm/lexer"
@@ -393,6 +394,72 @@ func TestAcceptance(t *testing.T) {
 	}
 }
 
+func TestOrderIsDeterministic(t *testing.T) {
+	const source = "" +
+		"fsm:f initial:z actions:a {" +
+		"  z:b3 :b1 { y m x2 x z x1 }" +
+		"  (b3) w z x3" +
+		"  (b1) v z x0" +
+		"  m e z *" +
+		"}"
+	const expected = "" +
+		"Initial: z\n" +
+		"Fsm: f\n" +
+		"Actions:a\n" +
+		"{\n" +
+		"  m {\n" +
+		"    e z {}\n" +
+		"  }\n" +
+		"  z {\n" +
+		"    y m {x2}\n" +
+		"    x z {x1}\n" +
+		"    w z {x3}\n" +
+		"    v z {x0}\n" +
+		"  }\n" +
+		"}\n"
+
+	// The analyzer keeps states, events and actions in maps, so run it a
+	// few times to give their iteration order the chance to differ.
+	for i := 0; i < 20; i++ {
+		osm := produceStateMachine(source)
+		for name, list := range map[string][]string{
+			"m z":         osm.States,
+			"e v w x y":   osm.Events,
+			"x0 x1 x2 x3": osm.Actions,
+		} {
+			if strings.Join(list, " ") != name {
+				t.Fatalf("expected '%s', but got '%s'", name, strings.Join(list, " "))
+			}
+		}
+		if osm.String() != expected {
+			t.Fatalf("expected %s, but got %s", expected, osm.String())
+		}
+	}
+}
+
+func TestStringOfMachineWithoutTransitions(t *testing.T) {
+	osm := &OptimizedStateMachine{Header: Header{Initial: "i", Fsm: "f"}}
+	if expected := "Initial: i\nFsm: f\nActions:\n{\n}\n"; osm.String() != expected {
+		t.Fatalf("expected %q, but got %q", expected, osm.String())
+	}
+}
+
+func TestRootFirstHierarchy(t *testing.T) {
+	syntaxBuilder := parser.NewFsmSyntaxBuilder()
+	syntaxParser := parser.NewParser(syntaxBuilder)
+	lexer.New(syntaxParser).Lex("fsm:f initial:i actions:a {(b1) * * * (b2):b1 * * * (b3) * * * i:b2 :b3 e i *}")
+	syntaxParser.HandleEvent("EOF", -1, -1)
+	semanticStateMachine := semanticanalyzer.New().Analyze(syntaxBuilder.GetFSM())
+
+	names := []string{}
+	for _, state := range RootFirstHierarchy(semanticStateMachine.States["i"]) {
+		names = append(names, state.Name)
+	}
+	if strings.Join(names, " ") != "b1 b2 b3 i" {
+		t.Fatalf("expected 'b1 b2 b3 i', but got '%s'", strings.Join(names, " "))
+	}
+}
+
 func produceStateMachineWithHeader(body string) OptimizedStateMachine {
 	source := "fsm:f initial:i actions:a " + body
 	return produceStateMachine(source)
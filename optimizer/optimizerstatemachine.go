@@ -1,7 +1,17 @@
 package optimizer
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
+// OptimizedStateMachine lists its States, Events and Actions sorted by name
+// and its Transitions in the order of States, so that generators produce
+// the same output for the same source. The SubTransitions of a state come
+// in the order its own transitions are declared, followed by the ones its
+// superstates add, nearest superstate first and superstates of the same
+// state in the reverse of their names, as in the reverse of
+// RootFirstHierarchy.
 type OptimizedStateMachine struct {
 	States      []string
 	Events      []string
@@ -10,11 +20,17 @@ type OptimizedStateMachine struct {
 	Transitions []Transition
 }
 
+// String formats the machine like a .sm source, one state block per
+// transition, which tests compare against.
 func (osm *OptimizedStateMachine) String() string {
-	transitionsString := osm.transitionsToString()
-	transitionsString = transitionsString[0 : len(transitionsString)-2]
+	transitionsString := ""
+	for _, line := range strings.SplitAfter(osm.transitionsToString(), "\n") {
+		if line != "" {
+			transitionsString += "  " + line
+		}
+	}
 	return fmt.Sprintf(
-		"Initial %s\nFsm: %s\nActions:%s\n{\n  %s\n",
+		"Initial: %s\nFsm: %s\nActions:%s\n{\n%s}\n",
 		osm.Header.Initial, osm.Header.Fsm, osm.Header.Actions, transitionsString,
 	)
 }
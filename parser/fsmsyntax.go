@@ -1,17 +1,29 @@
 package parser
 
-import "fmt"
+import (
+	"fmt"
+	"sort"
+
+	"github.com/larkvincer/dsl-fsm/diagnostics"
+	"github.com/larkvincer/dsl-fsm/parser/errortypes"
+)
 
 type FsmSyntax struct {
 	Headers []Header
 	Logic   []*FsmTransition
-	Errors  []syntaxError
+	Errors  []SyntaxError
 	Done    bool
 }
 
 type Header struct {
-	Name  string
-	Value string
+	Name          string
+	Value         string
+	NameLocation  diagnostics.Location
+	ValueLocation diagnostics.Location
+}
+
+func (header *Header) Location() diagnostics.Location {
+	return diagnostics.Span(header.NameLocation, header.ValueLocation)
 }
 
 func (header *Header) String() string {
@@ -19,7 +31,7 @@ func (header *Header) String() string {
 }
 
 func NullHeader() Header {
-	return Header{"", ""}
+	return Header{Name: "", Value: ""}
 }
 
 type FsmTransition struct {
@@ -28,33 +40,78 @@ type FsmTransition struct {
 }
 
 type SubTransition struct {
-	Event     string
-	NextState string
-	Actions   []string
+	Event             string
+	NextState         string
+	Actions           []string
+	EventLocation     diagnostics.Location
+	NextStateLocation diagnostics.Location
+	ActionLocations   []diagnostics.Location
 }
 
 type stateSpec struct {
-	Name          string
-	SuperStates   []string
-	EntryActions  []string
-	ExitActions   []string
-	AbstractState bool
+	Name                 string
+	SuperStates          []string
+	EntryActions         []string
+	ExitActions          []string
+	AbstractState        bool
+	NameLocation         diagnostics.Location
+	SuperStateLocations  []diagnostics.Location
+	EntryActionLocations []diagnostics.Location
+	ExitActionLocations  []diagnostics.Location
+}
+
+// ActionsLocation spans the entry and exit actions of the state, which may
+// be given in any order, or is empty if it has none.
+func (state *stateSpec) ActionsLocation() diagnostics.Location {
+	locations := append(append([]diagnostics.Location{}, state.EntryActionLocations...), state.ExitActionLocations...)
+	if len(locations) == 0 {
+		return diagnostics.Location{}
+	}
+	sort.Slice(locations, func(i, j int) bool {
+		if locations[i].Line != locations[j].Line {
+			return locations[i].Line < locations[j].Line
+		}
+		return locations[i].Column < locations[j].Column
+	})
+	return diagnostics.Span(locations[0], locations[len(locations)-1])
 }
 
-type syntaxError struct {
+type SyntaxError struct {
 	Type       string
 	Message    string
 	LineNumber int
 	Position   int
+	State      string
+	Event      string
+}
+
+func (error SyntaxError) Diagnostic() diagnostics.Diagnostic {
+	diagnostic := diagnostics.Diagnostic{Severity: diagnostics.Error, Code: error.Type}
+	if error.Type == errortypes.SYNTAX {
+		diagnostic.Message = "unrecognized character"
+	} else {
+		diagnostic.Message = fmt.Sprintf("unexpected %s in %s", error.Event, error.State)
+	}
+	if error.LineNumber > 0 {
+		// Lexical errors are reported 1-based, token errors 0-based.
+		column := error.Position + 1
+		if error.Type == errortypes.SYNTAX {
+			column = error.Position
+		}
+		diagnostic.Location = diagnostics.Location{
+			Line: error.LineNumber, Column: column,
+			EndLine: error.LineNumber, EndColumn: column,
+		}
+	}
+	return diagnostic
 }
 
 func (fsmSyntax *FsmSyntax) String() (result string) {
 	result = fsmSyntax.formatHeaders() + fsmSyntax.formatLogic()
 	if fsmSyntax.Done {
 		result += ".\n"
-	} else {
-		result += fsmSyntax.formatErrors()
 	}
+	result += fsmSyntax.formatErrors()
 
 	return
 }
@@ -63,6 +120,14 @@ func (fsmSyntax *FsmSyntax) GetErrors() string {
 	return fsmSyntax.formatErrors()
 }
 
+func (fsmSyntax *FsmSyntax) Diagnostics() []diagnostics.Diagnostic {
+	result := []diagnostics.Diagnostic{}
+	for _, error := range fsmSyntax.Errors {
+		result = append(result, error.Diagnostic())
+	}
+	return result
+}
+
 func (fsmSyntax *FsmSyntax) formatHeaders() string {
 	formattedHeaders := ""
 	for _, h := range fsmSyntax.Headers {
@@ -91,13 +156,14 @@ func formatTransition(trans *FsmTransition) string {
 }
 
 func (fsmSyntax *FsmSyntax) formatErrors() string {
-	if len(fsmSyntax.Errors) > 0 {
-		return formatError(fsmSyntax.Errors[0])
+	formattedErrors := ""
+	for _, error := range fsmSyntax.Errors {
+		formattedErrors += formatError(error)
 	}
-	return ""
+	return formattedErrors
 }
 
-func formatError(error syntaxError) string {
+func formatError(error SyntaxError) string {
 	return fmt.Sprintf("Syntax error: %s. %s. line %d, position %d.\n", error.Type, error.Message, error.LineNumber, error.Position)
 }
 
@@ -1,6 +1,7 @@
 package parser
 
 import (
+	"github.com/larkvincer/dsl-fsm/diagnostics"
 	"github.com/larkvincer/dsl-fsm/parser/errortypes"
 )
 
@@ -8,6 +9,8 @@ type FsmSyntaxBuilder struct {
 	fsmSyntax     *FsmSyntax
 	header        Header
 	parsedName    string
+	lineNumber    int
+	position      int
 	transition    *FsmTransition
 	subTransition SubTransition
 }
@@ -25,16 +28,17 @@ func (fsm *FsmSyntaxBuilder) GetFSM() *FsmSyntax {
 }
 
 func (fsm *FsmSyntaxBuilder) newHeaderWithName() {
-	fsm.header = Header{Name: fsm.parsedName}
+	fsm.header = Header{Name: fsm.parsedName, NameLocation: fsm.nameLocation()}
 }
 
 func (fsm *FsmSyntaxBuilder) addHeaderWithValue() {
 	fsm.header.Value = fsm.parsedName
+	fsm.header.ValueLocation = fsm.nameLocation()
 	fsm.fsmSyntax.Headers = append(fsm.fsmSyntax.Headers, fsm.header)
 }
 
 func (fsm *FsmSyntaxBuilder) setStateName() {
-	fsm.transition = &FsmTransition{State: stateSpec{Name: fsm.parsedName}}
+	fsm.transition = &FsmTransition{State: stateSpec{Name: fsm.parsedName, NameLocation: fsm.nameLocation()}}
 	fsm.fsmSyntax.Logic = append(fsm.fsmSyntax.Logic, fsm.transition)
 }
 
@@ -48,35 +52,40 @@ func (fsm *FsmSyntaxBuilder) setSuperStateName() {
 }
 
 func (fsm *FsmSyntaxBuilder) setEvent() {
-	fsm.subTransition = SubTransition{Event: fsm.parsedName}
+	fsm.subTransition = SubTransition{Event: fsm.parsedName, EventLocation: fsm.nameLocation()}
 }
 
 func (fsm *FsmSyntaxBuilder) setNullEvent() {
-	fsm.subTransition = SubTransition{Event: ""}
+	fsm.subTransition = SubTransition{Event: "", EventLocation: fsm.starLocation()}
 }
 
 func (fsm *FsmSyntaxBuilder) setEntryAction() {
 	fsm.transition.State.EntryActions = append(fsm.transition.State.EntryActions, fsm.parsedName)
+	fsm.transition.State.EntryActionLocations = append(fsm.transition.State.EntryActionLocations, fsm.nameLocation())
 }
 
 func (fsm *FsmSyntaxBuilder) setExitAction() {
 	fsm.transition.State.ExitActions = append(fsm.transition.State.ExitActions, fsm.parsedName)
+	fsm.transition.State.ExitActionLocations = append(fsm.transition.State.ExitActionLocations, fsm.nameLocation())
 }
 
 func (fsm *FsmSyntaxBuilder) setStateBase() {
 	fsm.transition.State.SuperStates = append(fsm.transition.State.SuperStates, fsm.parsedName)
+	fsm.transition.State.SuperStateLocations = append(fsm.transition.State.SuperStateLocations, fsm.nameLocation())
 }
 
 func (fsm *FsmSyntaxBuilder) setNextState() {
 	fsm.subTransition.NextState = fsm.parsedName
+	fsm.subTransition.NextStateLocation = fsm.nameLocation()
 }
 
 func (fsm *FsmSyntaxBuilder) setNullNextState() {
 	fsm.subTransition.NextState = ""
+	fsm.subTransition.NextStateLocation = fsm.starLocation()
 }
 
 func (fsm *FsmSyntaxBuilder) transitionWithAction() {
-	fsm.subTransition.Actions = append(fsm.subTransition.Actions, fsm.parsedName)
+	fsm.addAction()
 	fsm.transition.SubTransitions = append(fsm.transition.SubTransitions, fsm.subTransition)
 }
 
@@ -86,6 +95,7 @@ func (fsm *FsmSyntaxBuilder) transitionNullAction() {
 
 func (fsm *FsmSyntaxBuilder) addAction() {
 	fsm.subTransition.Actions = append(fsm.subTransition.Actions, fsm.parsedName)
+	fsm.subTransition.ActionLocations = append(fsm.subTransition.ActionLocations, fsm.nameLocation())
 }
 
 func (fsm *FsmSyntaxBuilder) transitionWithActions() {
@@ -93,28 +103,64 @@ func (fsm *FsmSyntaxBuilder) transitionWithActions() {
 }
 
 func (fsm *FsmSyntaxBuilder) headerError(state, event string, lineNumber, position int) {
-	fsm.fsmSyntax.Errors = append(fsm.fsmSyntax.Errors, syntaxError{errortypes.HEADER, state + "|" + event, lineNumber, position})
+	fsm.fsmSyntax.Errors = append(fsm.fsmSyntax.Errors, newSyntaxError(errortypes.HEADER, state, event, lineNumber, position))
 }
 
 func (fsm *FsmSyntaxBuilder) stateSpecError(state, event string, lineNumber, position int) {
-	fsm.fsmSyntax.Errors = append(fsm.fsmSyntax.Errors, syntaxError{errortypes.STATE, state + "|" + event, lineNumber, position})
+	fsm.fsmSyntax.Errors = append(fsm.fsmSyntax.Errors, newSyntaxError(errortypes.STATE, state, event, lineNumber, position))
 }
 
 func (fsm *FsmSyntaxBuilder) transitionError(state, event string, lineNumber, position int) {
-	fsm.fsmSyntax.Errors = append(fsm.fsmSyntax.Errors, syntaxError{errortypes.TRANSITION, state + "|" + event, lineNumber, position})
+	fsm.fsmSyntax.Errors = append(fsm.fsmSyntax.Errors, newSyntaxError(errortypes.TRANSITION, state, event, lineNumber, position))
 }
 
 func (fsm *FsmSyntaxBuilder) transitionGroupError(state, event string, lineNumber, position int) {
-	fsm.fsmSyntax.Errors = append(fsm.fsmSyntax.Errors, syntaxError{errortypes.TRANSITION_GROUP, state + "|" + event, lineNumber, position})
+	fsm.fsmSyntax.Errors = append(fsm.fsmSyntax.Errors, newSyntaxError(errortypes.TRANSITION_GROUP, state, event, lineNumber, position))
 }
 
 func (fsm *FsmSyntaxBuilder) endError(state, event string, lineNumber, position int) {
-	fsm.fsmSyntax.Errors = append(fsm.fsmSyntax.Errors, syntaxError{errortypes.END, state + "|" + event, lineNumber, position})
+	fsm.fsmSyntax.Errors = append(fsm.fsmSyntax.Errors, newSyntaxError(errortypes.END, state, event, lineNumber, position))
 }
 func (fsm *FsmSyntaxBuilder) syntaxError(lineNumber, position int) {
-	fsm.fsmSyntax.Errors = append(fsm.fsmSyntax.Errors, syntaxError{errortypes.SYNTAX, "", lineNumber, position})
+	fsm.fsmSyntax.Errors = append(fsm.fsmSyntax.Errors, SyntaxError{Type: errortypes.SYNTAX, LineNumber: lineNumber, Position: position})
 }
 
 func (fsm *FsmSyntaxBuilder) setName(name string) {
 	fsm.parsedName = name
 }
+
+func (fsm *FsmSyntaxBuilder) setPosition(lineNumber, position int) {
+	fsm.lineNumber = lineNumber
+	fsm.position = position
+}
+
+func (fsm *FsmSyntaxBuilder) nameLocation() diagnostics.Location {
+	return fsm.tokenLocation(len(fsm.parsedName))
+}
+
+func (fsm *FsmSyntaxBuilder) starLocation() diagnostics.Location {
+	return fsm.tokenLocation(1)
+}
+
+func (fsm *FsmSyntaxBuilder) tokenLocation(length int) diagnostics.Location {
+	if fsm.lineNumber <= 0 {
+		return diagnostics.Location{}
+	}
+	return diagnostics.Location{
+		Line:      fsm.lineNumber,
+		Column:    fsm.position + 1,
+		EndLine:   fsm.lineNumber,
+		EndColumn: fsm.position + length,
+	}
+}
+
+func newSyntaxError(errorType, state, event string, lineNumber, position int) SyntaxError {
+	return SyntaxError{
+		Type:       errorType,
+		Message:    state + "|" + event,
+		LineNumber: lineNumber,
+		Position:   position,
+		State:      state,
+		Event:      event,
+	}
+}
@@ -9,6 +9,17 @@ type Parser struct {
 	state         string
 	syntaxBuilder *SyntaxBuilder
 	transitions   []Transition
+	braceDepth    int
+	recovery      *recovery
+}
+
+// recovery holds the panic-mode state entered after a syntax error. Tokens
+// are skipped until the group the error occurred in is closed, or until a
+// token on a later line can start a new header, transition or subtransition
+// at the same nesting level.
+type recovery struct {
+	level     int
+	errorLine int
 }
 
 func NewParser(syntaxBuilder SyntaxBuilder) *Parser {
@@ -45,12 +56,21 @@ func (parser *Parser) Name(name string, lineNumber, position int) {
 }
 func (parser *Parser) Error(lineNumber, position int) {
 	(*parser.syntaxBuilder).syntaxError(lineNumber, position)
+	if parser.recovery == nil {
+		parser.startRecovery(lineNumber)
+	}
 }
 
 func (parser *Parser) HandleEvent(event string, line, position int) {
+	if parser.recovery != nil {
+		parser.recover(event, line, position)
+		return
+	}
 	for _, transition := range parser.transitions {
 		if transition.currentState == parser.state && transition.event == event {
 			parser.state = transition.newState
+			parser.trackBraceDepth(event)
+			(*parser.syntaxBuilder).setPosition(line, position)
 			if transition.action != nil {
 				transition.action(parser.syntaxBuilder)
 			}
@@ -58,6 +78,86 @@ func (parser *Parser) HandleEvent(event string, line, position int) {
 		}
 	}
 	parser.handleEventError(event, line, position)
+	parser.startRecovery(line)
+	parser.recover(event, line, position)
+}
+
+func (parser *Parser) trackBraceDepth(event string) {
+	switch event {
+	case tokens.OPEN_BRACE:
+		parser.braceDepth++
+	case tokens.CLOSE_BRACE:
+		parser.braceDepth--
+	}
+}
+
+func (parser *Parser) startRecovery(line int) {
+	parser.recovery = &recovery{level: recoveryLevel(parser.state), errorLine: line}
+}
+
+func recoveryLevel(state string) int {
+	switch state {
+	case states.HEADER, states.HEADER_COLON, states.HEADER_VALUE:
+		return 0
+	case states.SUBTRANSITION_GROUP,
+		states.GROUP_EVENT,
+		states.GROUP_NEXT_STATE,
+		states.GROUP_ACTION_GROUP,
+		states.GROUP_ACTION_GROUP_NAME:
+		return 2
+	case states.END:
+		return -1
+	}
+	return 1
+}
+
+func (parser *Parser) recover(event string, line, position int) {
+	level := parser.recovery.level
+	if level < 0 {
+		return
+	}
+	atLevel := parser.braceDepth == level
+	switch event {
+	case tokens.OPEN_BRACE:
+		if atLevel && level == 0 {
+			parser.resume(event, line, position)
+		} else {
+			parser.braceDepth++
+		}
+	case tokens.CLOSE_BRACE:
+		if atLevel && level > 0 {
+			parser.resume(event, line, position)
+		} else {
+			parser.braceDepth--
+		}
+	case tokens.NAME, tokens.STAR, tokens.OPEN_PAREN:
+		if atLevel && line > parser.recovery.errorLine && canStartAtLevel(event, level) {
+			parser.resume(event, line, position)
+		}
+	}
+}
+
+func canStartAtLevel(event string, level int) bool {
+	switch level {
+	case 0:
+		return event == tokens.NAME
+	case 1:
+		return event == tokens.NAME || event == tokens.OPEN_PAREN
+	}
+	return event == tokens.NAME || event == tokens.STAR
+}
+
+func (parser *Parser) resume(event string, line, position int) {
+	switch parser.recovery.level {
+	case 0:
+		parser.state = states.HEADER
+	case 1:
+		parser.state = states.STATE_SPEC
+	default:
+		parser.state = states.SUBTRANSITION_GROUP
+	}
+	parser.recovery = nil
+	parser.HandleEvent(event, line, position)
 }
 
 func (parser *Parser) handleEventError(event string, lineNumber, position int) {
@@ -1,9 +1,12 @@
 package parser
 
 import (
+	"reflect"
 	"testing"
 
+	"github.com/larkvincer/dsl-fsm/diagnostics"
 	"github.com/larkvincer/dsl-fsm/lexer"
+	"github.com/larkvincer/dsl-fsm/parser/errortypes"
 )
 
 type parserTest struct {
@@ -90,6 +93,112 @@ func TestParseErrors(t *testing.T) {
 	}
 }
 
+func TestErrorRecovery(t *testing.T) {
+	testTable := []parserTest{
+		{"resume at next header", "A B: c\nFSM: f\n{s e s a}",
+			"Syntax error: HEADER. HEADER_COLON|NAME. line 1, position 2.\n"},
+		{"resume at next transition",
+			"" +
+				"{\n" +
+				"  s1 e1 s2 )\n" +
+				"  s2 e2 s1 a\n" +
+				"  s3 { e3 ( s1 a }\n" +
+				"  s4 e4 s1 a\n" +
+				"}",
+			"" +
+				"Syntax error: TRANSITION. SINGLE_NEXT_STATE|). line 2, position 11.\n" +
+				"Syntax error: TRANSITION_GROUP. GROUP_EVENT|(. line 4, position 10.\n"},
+		{"resume at next subtransition",
+			"" +
+				"{\n" +
+				"  s {\n" +
+				"    e1 s (\n" +
+				"    e2 s a\n" +
+				"  }\n" +
+				"  s e3 s )\n" +
+				"}",
+			"" +
+				"Syntax error: TRANSITION_GROUP. GROUP_NEXT_STATE|(. line 3, position 9.\n" +
+				"Syntax error: TRANSITION. SINGLE_NEXT_STATE|). line 6, position 9.\n"},
+		{"nested braces are skipped", "{s ( {a b} }", "Syntax error: STATE. STATE_MODIFIER|(. line 1, position 3.\n"},
+		{"tokens after end are reported once", "{} x y {", "Syntax error: END. END|NAME. line 1, position 3.\n"},
+		{"every lexical error is reported",
+			"{s e . s a\n t . e s a}",
+			"" +
+				"Syntax error: SYNTAX. . line 1, position 6.\n" +
+				"Syntax error: SYNTAX. . line 2, position 4.\n"},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			syntaxBuilder := NewFsmSyntaxBuilder()
+			parser := NewParser(syntaxBuilder)
+			lexer.New(parser).Lex(testCase.source)
+			parser.HandleEvent("EOF", -1, -1)
+			got := syntaxBuilder.GetFSM().GetErrors()
+			if got != testCase.expected {
+				t.Fatalf("expected '%s' for %s, but got '%s'", testCase.expected, testCase.source, got)
+			}
+		})
+	}
+
+	t.Run("transitions after an error are still parsed", func(t *testing.T) {
+		got := parseSource("{\n  s1 e1 s2 )\n  s2 e2 s1 a\n}")
+		expected := "" +
+			"{\n" +
+			"  s1 {\n" +
+			"  }\n" +
+			"  s2 e2 s1 a\n" +
+			"}\n" +
+			".\n" +
+			"Syntax error: TRANSITION. SINGLE_NEXT_STATE|). line 2, position 11.\n"
+		if got != expected {
+			t.Fatalf("expected '%s', but got '%s'", expected, got)
+		}
+	})
+}
+
+func TestErrorDiagnostics(t *testing.T) {
+	type diagnosticTest struct {
+		name     string
+		source   string
+		expected diagnostics.Diagnostic
+	}
+
+	testTable := []diagnosticTest{
+		{"unexpected token", "A {s e ns a}", diagnostics.Diagnostic{
+			Severity: diagnostics.Error,
+			Code:     errortypes.HEADER,
+			Message:  "unexpected { in HEADER_COLON",
+			Location: diagnostics.Location{Line: 1, Column: 3, EndLine: 1, EndColumn: 3},
+		}},
+		{"lexical error", "{. e ns a}", diagnostics.Diagnostic{
+			Severity: diagnostics.Error,
+			Code:     errortypes.SYNTAX,
+			Message:  "unrecognized character",
+			Location: diagnostics.Location{Line: 1, Column: 2, EndLine: 1, EndColumn: 2},
+		}},
+		{"unexpected end of file", "{", diagnostics.Diagnostic{
+			Severity: diagnostics.Error,
+			Code:     errortypes.STATE,
+			Message:  "unexpected EOF in STATE_SPEC",
+		}},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			syntaxBuilder := NewFsmSyntaxBuilder()
+			parser := NewParser(syntaxBuilder)
+			lexer.New(parser).Lex(testCase.source)
+			parser.HandleEvent("EOF", -1, -1)
+			got := syntaxBuilder.GetFSM().Diagnostics()
+			if len(got) == 0 || !reflect.DeepEqual(got[0], testCase.expected) {
+				t.Fatalf("expected '%v' for %s, but got '%v'", testCase.expected, testCase.source, got)
+			}
+		})
+	}
+}
+
 func TestIntegration(t *testing.T) {
 	testTable := []parserTest{
 		{
@@ -227,6 +336,35 @@ func TestIntegration(t *testing.T) {
 	}
 }
 
+func TestStateActionLocations(t *testing.T) {
+	syntaxBuilder := NewFsmSyntaxBuilder()
+	parser := NewParser(syntaxBuilder)
+	lexer.New(parser).Lex("{\n  s >x1 <e1\n    <{e2 e3} e ns *\n  ns e s *\n}")
+	parser.HandleEvent("EOF", -1, -1)
+	state := syntaxBuilder.GetFSM().Logic[0].State
+
+	location := func(line, column, endColumn int) diagnostics.Location {
+		return diagnostics.Location{Line: line, Column: column, EndLine: line, EndColumn: endColumn}
+	}
+	expectedEntry := []diagnostics.Location{location(2, 10, 11), location(3, 7, 8), location(3, 10, 11)}
+	if !reflect.DeepEqual(state.EntryActionLocations, expectedEntry) {
+		t.Fatalf("expected entry actions at %v, but got %v", expectedEntry, state.EntryActionLocations)
+	}
+	expectedExit := []diagnostics.Location{location(2, 6, 7)}
+	if !reflect.DeepEqual(state.ExitActionLocations, expectedExit) {
+		t.Fatalf("expected exit actions at %v, but got %v", expectedExit, state.ExitActionLocations)
+	}
+	expectedSpan := diagnostics.Location{Line: 2, Column: 6, EndLine: 3, EndColumn: 11}
+	if state.ActionsLocation() != expectedSpan {
+		t.Fatalf("expected the actions to span %v, but got %v", expectedSpan, state.ActionsLocation())
+	}
+
+	noActions := syntaxBuilder.GetFSM().Logic[1].State
+	if noActions.ActionsLocation() != (diagnostics.Location{}) {
+		t.Fatalf("expected no location without actions, but got %v", noActions.ActionsLocation())
+	}
+}
+
 func parseSource(source string) string {
 	syntaxBuilder := NewFsmSyntaxBuilder()
 	parser := NewParser(syntaxBuilder)
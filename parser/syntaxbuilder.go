@@ -24,4 +24,5 @@ type SyntaxBuilder interface {
 	endError(state, event string, lineNumber, position int)
 	syntaxError(lineNumber, position int)
 	setName(name string)
+	setPosition(lineNumber, position int)
 }
@@ -0,0 +1,152 @@
+package runtime
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/larkvincer/dsl-fsm/compiler"
+	"github.com/larkvincer/dsl-fsm/diagnostics"
+	"github.com/larkvincer/dsl-fsm/optimizer"
+)
+
+var (
+	ErrUnknownEvent        = errors.New("unknown event")
+	ErrUnhandledTransition = errors.New("unhandled transition")
+)
+
+// Actions maps every action name of a state machine to the function that
+// performs it.
+type Actions map[string]func()
+
+// Machine behaves like the generated Java class: Fire switches to the next
+// state before it runs the transition's actions, so an action sees the new
+// state in CurrentState. Events the current state does not handle go to
+// UnhandledTransition; when it is nil Fire reports ErrUnhandledTransition.
+type Machine struct {
+	// UnhandledTransition is called, when set, for events the current state does not handle.
+	UnhandledTransition func(state, event string)
+	state               string
+	events              map[string]bool
+	transitions         map[string]map[string]optimizer.SubTransition
+	actions             Actions
+}
+
+// New returns a Machine in the initial state of osm. Every action of osm
+// needs an entry in actions.
+func New(osm *optimizer.OptimizedStateMachine, actions Actions) (*Machine, error) {
+	missing := []string{}
+	for _, action := range osm.Actions {
+		if actions[action] == nil {
+			missing = append(missing, action)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("no function for actions %s", strings.Join(missing, ", "))
+	}
+
+	machine := &Machine{
+		state:       osm.Header.Initial,
+		events:      make(map[string]bool),
+		transitions: make(map[string]map[string]optimizer.SubTransition),
+		actions:     actions,
+	}
+	for _, event := range osm.Events {
+		machine.events[event] = true
+	}
+	for _, transition := range osm.Transitions {
+		subTransitions := make(map[string]optimizer.SubTransition)
+		for _, subTransition := range transition.SubTransitions {
+			subTransitions[subTransition.Event] = subTransition
+		}
+		machine.transitions[transition.CurrentState] = subTransitions
+	}
+	return machine, nil
+}
+
+// NewWithReceiver resolves every action to a method of receiver that takes
+// no arguments, named like the action or like the action capitalized, so
+// alarmOn can be implemented by AlarmOn.
+func NewWithReceiver(osm *optimizer.OptimizedStateMachine, receiver interface{}) (*Machine, error) {
+	actions := Actions{}
+	value := reflect.ValueOf(receiver)
+	for _, action := range osm.Actions {
+		method := value.MethodByName(capitalize(action))
+		if !method.IsValid() {
+			method = value.MethodByName(action)
+		}
+		if !method.IsValid() {
+			return nil, fmt.Errorf("%T has no method for action %s", receiver, action)
+		}
+		function, ok := method.Interface().(func())
+		if !ok {
+			return nil, fmt.Errorf("method for action %s of %T must not take arguments or return values", action, receiver)
+		}
+		actions[action] = function
+	}
+	return New(osm, actions)
+}
+
+// Load compiles a state machine source, e.g. a .sm file, and returns a
+// Machine for it.
+func Load(src io.Reader, actions Actions) (*Machine, error) {
+	result, err := compiler.Compile(src, compiler.Options{})
+	if err != nil {
+		return nil, compilationError(err, result.Diagnostics)
+	}
+	return New(result.Optimized, actions)
+}
+
+func compilationError(err error, all []diagnostics.Diagnostic) error {
+	messages := []string{}
+	for _, diagnostic := range all {
+		if diagnostic.Severity == diagnostics.Error {
+			messages = append(messages, diagnostic.String())
+		}
+	}
+	if len(messages) == 0 {
+		return err
+	}
+	return fmt.Errorf("%w: %s", err, strings.Join(messages, "; "))
+}
+
+func (machine *Machine) CurrentState() string {
+	return machine.state
+}
+
+// Fire handles event in the current state. It returns ErrUnknownEvent for
+// events the state machine does not declare.
+func (machine *Machine) Fire(event string) error {
+	if !machine.events[event] {
+		return fmt.Errorf("%w %q", ErrUnknownEvent, event)
+	}
+	subTransition, ok := machine.transitions[machine.state][event]
+	if !ok {
+		if machine.UnhandledTransition == nil {
+			return fmt.Errorf("%w: state %s, event %s", ErrUnhandledTransition, machine.state, event)
+		}
+		machine.UnhandledTransition(machine.state, event)
+		return nil
+	}
+	machine.setState(subTransition.NextState)
+	for _, action := range subTransition.Actions {
+		machine.actions[action]()
+	}
+	return nil
+}
+
+func (machine *Machine) setState(state string) {
+	machine.state = state
+}
+
+func capitalize(name string) string {
+	first, size := utf8.DecodeRuneInString(name)
+	if first == utf8.RuneError {
+		return name
+	}
+	return string(unicode.ToUpper(first)) + name[size:]
+}
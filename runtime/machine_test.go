@@ -0,0 +1,164 @@
+package runtime
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/larkvincer/dsl-fsm/compiler"
+	"github.com/larkvincer/dsl-fsm/optimizer"
+)
+
+const twoCoinTurnstile = "" +
+	"Actions: Turnstile\n" +
+	"FSM: TwoCoinTurnstile\n" +
+	"Initial: Locked\n" +
+	"{\n" +
+	"  (Base) Reset Locked lock\n" +
+	"  Locked : Base {\n" +
+	"    Pass Alarming {}\n" +
+	"    Coin FirstCoin {}\n" +
+	"  }\n" +
+	"  Alarming : Base <alarmOn >alarmOff {}\n" +
+	"  FirstCoin : Base {\n" +
+	"    Pass Alarming {}\n" +
+	"    Coin Unlocked unlock\n" +
+	"  }\n" +
+	"  Unlocked : Base {\n" +
+	"    Pass Locked lock\n" +
+	"    Coin * thankyou\n" +
+	"  }\n" +
+	"}\n"
+
+// The same trace the generated code prints for these events.
+var twoCoinTurnstileEvents = []string{"Coin", "Coin", "Coin", "Pass", "Pass", "Pass", "Reset", "Reset"}
+
+const twoCoinTurnstileTrace = "" +
+	"unlock\n" +
+	"thankyou\n" +
+	"lock\n" +
+	"alarmOn\n" +
+	"unhandled Alarming Pass\n" +
+	"alarmOff\n" +
+	"lock\n" +
+	"lock\n" +
+	"Locked\n"
+
+type turnstile struct {
+	trace *strings.Builder
+}
+
+func (t turnstile) AlarmOff() { t.trace.WriteString("alarmOff\n") }
+func (t turnstile) AlarmOn()  { t.trace.WriteString("alarmOn\n") }
+func (t turnstile) Lock()     { t.trace.WriteString("lock\n") }
+func (t turnstile) Thankyou() { t.trace.WriteString("thankyou\n") }
+func (t turnstile) Unlock()   { t.trace.WriteString("unlock\n") }
+
+func loadOptimized(source string) (*optimizer.OptimizedStateMachine, error) {
+	result, err := compiler.Compile(strings.NewReader(source), compiler.Options{})
+	return result.Optimized, err
+}
+
+func runTrace(t *testing.T, machine *Machine, trace *strings.Builder) string {
+	t.Helper()
+	machine.UnhandledTransition = func(state, event string) {
+		trace.WriteString("unhandled " + state + " " + event + "\n")
+	}
+	for _, event := range twoCoinTurnstileEvents {
+		if err := machine.Fire(event); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return trace.String() + machine.CurrentState() + "\n"
+}
+
+func TestMachineWithActionRegistry(t *testing.T) {
+	trace := &strings.Builder{}
+	actions := Actions{}
+	for _, action := range []string{"alarmOff", "alarmOn", "lock", "thankyou", "unlock"} {
+		action := action
+		actions[action] = func() { trace.WriteString(action + "\n") }
+	}
+	machine, err := Load(strings.NewReader(twoCoinTurnstile), actions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if machine.CurrentState() != "Locked" {
+		t.Fatalf("expected initial state Locked, got %s", machine.CurrentState())
+	}
+	if output := runTrace(t, machine, trace); output != twoCoinTurnstileTrace {
+		t.Fatalf("expected\n%s\nbut got\n%s", twoCoinTurnstileTrace, output)
+	}
+}
+
+func TestMachineWithReceiver(t *testing.T) {
+	result, err := loadOptimized(twoCoinTurnstile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	trace := &strings.Builder{}
+	machine, err := NewWithReceiver(result, turnstile{trace: trace})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if output := runTrace(t, machine, trace); output != twoCoinTurnstileTrace {
+		t.Fatalf("expected\n%s\nbut got\n%s", twoCoinTurnstileTrace, output)
+	}
+}
+
+func TestStateIsSetBeforeActionsRun(t *testing.T) {
+	var machine *Machine
+	states := []string{}
+	record := func() { states = append(states, machine.CurrentState()) }
+	machine, err := Load(
+		strings.NewReader("FSM: Door\nInitial: closed\n{\nclosed open opened {leave enter}\nopened <enter >leave close closed {}\n}\n"),
+		Actions{"enter": record, "leave": record},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, event := range []string{"open", "close"} {
+		if err := machine.Fire(event); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if strings.Join(states, " ") != "opened opened opened closed" {
+		t.Fatalf("expected actions to see the next state, got %v", states)
+	}
+}
+
+func TestFireErrors(t *testing.T) {
+	machine, err := Load(strings.NewReader("FSM: Door\nInitial: closed\n{\nclosed open opened {}\nopened close closed {}\n}\n"), Actions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := machine.Fire("knock"); !errors.Is(err, ErrUnknownEvent) {
+		t.Errorf("expected ErrUnknownEvent, got %v", err)
+	}
+	if err := machine.Fire("close"); !errors.Is(err, ErrUnhandledTransition) {
+		t.Errorf("expected ErrUnhandledTransition, got %v", err)
+	}
+	if machine.CurrentState() != "closed" {
+		t.Errorf("expected errors to leave the state alone, got %s", machine.CurrentState())
+	}
+}
+
+func TestMissingActions(t *testing.T) {
+	if _, err := Load(strings.NewReader(twoCoinTurnstile), Actions{"lock": func() {}}); err == nil {
+		t.Error("expected an error for actions without a function")
+	}
+	result, err := loadOptimized(twoCoinTurnstile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewWithReceiver(result, struct{}{}); err == nil {
+		t.Error("expected an error for a receiver without action methods")
+	}
+}
+
+func TestLoadReportsCompilationErrors(t *testing.T) {
+	_, err := Load(strings.NewReader("{s e}"), Actions{})
+	if err == nil || !strings.Contains(err.Error(), "compilation failed") {
+		t.Fatalf("expected a compilation error, got %v", err)
+	}
+}
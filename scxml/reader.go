@@ -0,0 +1,447 @@
+package scxml
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/larkvincer/dsl-fsm/diagnostics"
+	"github.com/larkvincer/dsl-fsm/parser"
+)
+
+const (
+	Namespace = "http://www.w3.org/2005/07/scxml"
+	// SMCNamespace holds the attributes SCXML has no equivalent for, like
+	// smc:actions for the Actions header.
+	SMCNamespace = "https://github.com/larkvincer/dsl-fsm"
+)
+
+// Codes of the diagnostics Read reports.
+const (
+	INVALID_XML       = "INVALID_XML"
+	UNSUPPORTED_SCXML = "UNSUPPORTED_SCXML"
+)
+
+// Error is a document that is not well-formed XML, or a construct of it
+// that the state machine language cannot express.
+type Error struct {
+	Code     string
+	Message  string
+	Location diagnostics.Location
+}
+
+func (err *Error) Error() string {
+	if err.Location.Line == 0 {
+		return err.Message
+	}
+	return fmt.Sprintf("line %d: %s", err.Location.Line, err.Message)
+}
+
+func (err *Error) Diagnostic() diagnostics.Diagnostic {
+	return diagnostics.Diagnostic{Severity: diagnostics.Error, Code: err.Code, Message: err.Message, Location: err.Location}
+}
+
+// node is any element of the document; the element name tells which of the
+// fields apply.
+type node struct {
+	XMLName xml.Name
+	Name    string  `xml:"name,attr"`
+	Actions string  `xml:"https://github.com/larkvincer/dsl-fsm actions,attr"`
+	ID      string  `xml:"id,attr"`
+	Event   string  `xml:"event,attr"`
+	Target  *string `xml:"target,attr"`
+	Cond    string  `xml:"cond,attr"`
+	Initial string  `xml:"initial,attr"`
+	Nodes   []node  `xml:",any"`
+	Text    string  `xml:",chardata"`
+	// tagEnd is the offset just past the element's start tag.
+	tagEnd int
+}
+
+func (element *node) UnmarshalXML(decoder *xml.Decoder, start xml.StartElement) error {
+	tagEnd := int(decoder.InputOffset())
+	type plainNode node
+	if err := decoder.DecodeElement((*plainNode)(element), &start); err != nil {
+		return err
+	}
+	element.tagEnd = tagEnd
+	return nil
+}
+
+// Read parses an SCXML document into the syntax the .sm parser produces,
+// so that it goes through the same semantic analysis. Only the subset that
+// SCXML shares with the .sm language is accepted: <state> and <final>
+// elements, whose nesting gives the superstates, <transition>s with events
+// and at most one target, and <script> elements calling one action each,
+// like "unlock();", in <onentry>, <onexit> and <transition>. A state with
+// substates is an abstract superstate, as SCXML never rests in it; a
+// transition to it enters its initial substate instead, as SCXML does. A
+// transition without a target in an atomic state becomes "*", which leaves
+// and re-enters the state; one in a compound state has no equivalent, as
+// the state machine would have to stay in whichever substate it is in.
+// Anything else, like conditions, eventless transitions, <parallel> or
+// <history>, is reported as an error rather than dropped. Errors are
+// *Error values located in the document, and so is the syntax, so that
+// diagnostics point into it.
+func Read(src io.Reader) (*parser.FsmSyntax, error) {
+	source, err := io.ReadAll(src)
+	if err != nil {
+		return nil, err
+	}
+	doc := node{}
+	if err := xml.NewDecoder(bytes.NewReader(source)).Decode(&doc); err != nil {
+		return nil, decodeError(err)
+	}
+	reader := &reader{
+		source: source,
+		syntax: &parser.FsmSyntax{Done: true},
+		states: map[string]*node{},
+	}
+	if doc.XMLName.Local != "scxml" {
+		return nil, reader.errorf(&doc, "the document element is <%s>, not <scxml>", doc.XMLName.Local)
+	}
+	if doc.XMLName.Space != Namespace && doc.XMLName.Space != "" {
+		return nil, reader.errorf(&doc, "<scxml> is not in the SCXML namespace %s", Namespace)
+	}
+	reader.indexStates(doc.Nodes)
+
+	reader.addHeader("FSM", doc.Name, reader.attributeLocation(&doc, "name"))
+	initial := doc.Initial
+	if initial == "" {
+		initial = firstState(doc.Nodes)
+	}
+	if initial != "" {
+		entered, err := reader.enteredState(initial, &doc)
+		if err != nil {
+			return nil, err
+		}
+		reader.addHeader("Initial", entered, reader.attributeLocation(&doc, "initial"))
+	}
+	reader.addHeader("Actions", doc.Actions, reader.attributeLocation(&doc, "actions"))
+	for i := range doc.Nodes {
+		if err := reader.readState(&doc.Nodes[i], nil); err != nil {
+			return nil, err
+		}
+	}
+	return reader.syntax, nil
+}
+
+type reader struct {
+	source []byte
+	syntax *parser.FsmSyntax
+	states map[string]*node
+}
+
+func (reader *reader) indexStates(nodes []node) {
+	for i := range nodes {
+		if nodes[i].ID != "" {
+			reader.states[nodes[i].ID] = &nodes[i]
+		}
+		reader.indexStates(nodes[i].Nodes)
+	}
+}
+
+func (reader *reader) addHeader(name, value string, location diagnostics.Location) {
+	if value != "" {
+		reader.syntax.Headers = append(reader.syntax.Headers, parser.Header{
+			Name: name, Value: value, NameLocation: location, ValueLocation: location,
+		})
+	}
+}
+
+func (reader *reader) readState(element *node, superState *node) error {
+	switch element.XMLName.Local {
+	case "state", "final":
+	case "datamodel":
+		if superState == nil {
+			return nil
+		}
+		return reader.unsupported(element, superState)
+	default:
+		return reader.unsupported(element, superState)
+	}
+	if element.ID == "" {
+		return reader.errorf(element, "<%s> without an id", element.XMLName.Local)
+	}
+
+	transition := &parser.FsmTransition{}
+	transition.State.Name = element.ID
+	transition.State.NameLocation = reader.attributeLocation(element, "id")
+	if superState != nil {
+		transition.State.SuperStates = []string{superState.ID}
+		transition.State.SuperStateLocations = []diagnostics.Location{reader.attributeLocation(superState, "id")}
+	}
+	reader.syntax.Logic = append(reader.syntax.Logic, transition)
+
+	substates := []*node{}
+	for i := range element.Nodes {
+		child := &element.Nodes[i]
+		var err error
+		if isSubstate(child) {
+			substates = append(substates, child)
+			continue
+		}
+		switch child.XMLName.Local {
+		case "onentry":
+			transition.State.EntryActions, err = reader.actions(child, element)
+		case "onexit":
+			transition.State.ExitActions, err = reader.actions(child, element)
+		case "transition":
+			err = reader.readTransition(transition, child, element)
+		default:
+			err = reader.unsupported(child, element)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	transition.State.AbstractState = len(substates) > 0
+	for _, substate := range substates {
+		if err := reader.readState(substate, element); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (reader *reader) readTransition(transition *parser.FsmTransition, element, state *node) error {
+	if element.Cond != "" {
+		return reader.errorf(element,
+			"transition of state %s has a condition, which the state machine language cannot express", state.ID)
+	}
+	events := strings.Fields(element.Event)
+	if len(events) == 0 {
+		return reader.errorf(element, "transition of state %s has no event", state.ID)
+	}
+	nextState := ""
+	nextStateLocation := diagnostics.Location{}
+	if element.Target == nil && hasSubstates(state) {
+		return reader.errorf(element, "transition of compound state %s on %s has no target, "+
+			"which the state machine language cannot express", state.ID, element.Event)
+	}
+	if element.Target != nil {
+		targets := strings.Fields(*element.Target)
+		if len(targets) != 1 {
+			return reader.errorf(element, "transition of state %s on %s needs exactly one target", state.ID, element.Event)
+		}
+		var err error
+		if nextState, err = reader.enteredState(targets[0], element); err != nil {
+			return err
+		}
+		nextStateLocation = reader.attributeLocation(element, "target")
+	}
+	transitionActions, err := reader.actions(element, state)
+	if err != nil {
+		return err
+	}
+	for _, event := range events {
+		transition.SubTransitions = append(transition.SubTransitions, parser.SubTransition{
+			Event:             event,
+			NextState:         nextState,
+			Actions:           transitionActions,
+			EventLocation:     reader.wordLocation(element, "event", event),
+			NextStateLocation: nextStateLocation,
+		})
+	}
+	return nil
+}
+
+// enteredState follows the SCXML rule that entering a compound state enters
+// its initial substate: the one its initial attribute names, or else its
+// first child state. Unknown ids are left to the semantic analyzer.
+func (reader *reader) enteredState(id string, reference *node) (string, error) {
+	visited := map[string]bool{}
+	for {
+		state, ok := reader.states[id]
+		if !ok {
+			return id, nil
+		}
+		if visited[id] {
+			return "", reader.errorf(reference, "the initial substates of %s form a cycle", id)
+		}
+		visited[id] = true
+		switch state.XMLName.Local {
+		case "state", "final":
+		default:
+			return "", reader.errorf(reference, "%s is a <%s>, which the state machine language cannot enter",
+				id, state.XMLName.Local)
+		}
+		next := state.Initial
+		if next == "" {
+			next = firstState(state.Nodes)
+		}
+		if next == "" {
+			return id, nil
+		}
+		id = next
+	}
+}
+
+// actions reads the <script> children of element, each calling actions
+// like "alarmOn();".
+func (reader *reader) actions(element, state *node) ([]string, error) {
+	result := []string{}
+	for i := range element.Nodes {
+		child := &element.Nodes[i]
+		if child.XMLName.Local != "script" {
+			return nil, reader.unsupported(child, state)
+		}
+		for _, statement := range strings.Split(child.Text, ";") {
+			statement = strings.TrimSpace(statement)
+			if statement == "" {
+				continue
+			}
+			action := strings.TrimSuffix(statement, "()")
+			if action == statement || !isIdentifier(action) {
+				return nil, reader.errorf(child,
+					"script %q in state %s is not a list of action calls like \"unlock();\"", child.Text, state.ID)
+			}
+			result = append(result, action)
+		}
+	}
+	return result, nil
+}
+
+func isSubstate(element *node) bool {
+	switch element.XMLName.Local {
+	case "state", "final", "parallel", "history", "initial":
+		return true
+	}
+	return false
+}
+
+func hasSubstates(state *node) bool {
+	for i := range state.Nodes {
+		if isSubstate(&state.Nodes[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// firstState returns the id of the first child state, which SCXML enters
+// when a document or compound state has no initial attribute.
+func firstState(nodes []node) string {
+	for _, child := range nodes {
+		if child.XMLName.Local == "state" || child.XMLName.Local == "final" {
+			return child.ID
+		}
+	}
+	return ""
+}
+
+func (reader *reader) unsupported(element, state *node) error {
+	if state == nil {
+		return reader.errorf(element, "unsupported SCXML element <%s>", element.XMLName.Local)
+	}
+	return reader.errorf(element, "unsupported SCXML element <%s> in state %s", element.XMLName.Local, state.ID)
+}
+
+func (reader *reader) errorf(element *node, format string, arguments ...interface{}) error {
+	return &Error{
+		Code:     UNSUPPORTED_SCXML,
+		Message:  fmt.Sprintf(format, arguments...),
+		Location: reader.tagLocation(element),
+	}
+}
+
+// decodeError locates the syntax errors of encoding/xml on their line; it
+// knows no column.
+func decodeError(err error) error {
+	readError := &Error{Code: INVALID_XML, Message: err.Error()}
+	if syntaxError, ok := err.(*xml.SyntaxError); ok {
+		readError.Message = syntaxError.Msg
+		readError.Location = diagnostics.Location{Line: syntaxError.Line, EndLine: syntaxError.Line}
+	} else if err == io.EOF {
+		readError.Message = "the document has no root element"
+	}
+	return readError
+}
+
+// tagStart finds the '<' that opens the element's start tag; attribute
+// values cannot contain a literal '<'.
+func (reader *reader) tagStart(element *node) int {
+	return bytes.LastIndexByte(reader.source[:element.tagEnd], '<')
+}
+
+// tagLocation spans the element name in its start tag.
+func (reader *reader) tagLocation(element *node) diagnostics.Location {
+	start := reader.tagStart(element) + 1
+	length := len(element.XMLName.Local)
+	if nameEnd := bytes.IndexAny(reader.source[start:element.tagEnd], " \t\r\n/>"); nameEnd > 0 {
+		length = nameEnd
+	}
+	return reader.location(start, start+length)
+}
+
+// attributeLocation spans the value of an attribute of the element's start
+// tag, or the element name when the attribute is missing.
+func (reader *reader) attributeLocation(element *node, attribute string) diagnostics.Location {
+	if start, end, ok := reader.attributeValue(element, attribute); ok {
+		return reader.location(start, end)
+	}
+	return reader.tagLocation(element)
+}
+
+// wordLocation spans one word of a space separated attribute value.
+func (reader *reader) wordLocation(element *node, attribute, word string) diagnostics.Location {
+	start, end, ok := reader.attributeValue(element, attribute)
+	if !ok {
+		return reader.tagLocation(element)
+	}
+	for _, match := range wordPattern.FindAllIndex(reader.source[start:end], -1) {
+		if string(reader.source[start+match[0]:start+match[1]]) == word {
+			return reader.location(start+match[0], start+match[1])
+		}
+	}
+	return reader.location(start, end)
+}
+
+var wordPattern = regexp.MustCompile(`\S+`)
+
+func (reader *reader) attributeValue(element *node, attribute string) (int, int, bool) {
+	tagStart := reader.tagStart(element)
+	tag := reader.source[tagStart:element.tagEnd]
+	pattern := regexp.MustCompile(`\s(?:[\w.-]+:)?` + regexp.QuoteMeta(attribute) + `\s*=\s*(["'])`)
+	match := pattern.FindSubmatchIndex(tag)
+	if match == nil {
+		return 0, 0, false
+	}
+	start := tagStart + match[1]
+	end := start + bytes.IndexByte(reader.source[start:element.tagEnd], tag[match[2]])
+	return start, end, end >= start
+}
+
+// location converts a range of byte offsets into a 1-based Location with an
+// inclusive end column.
+func (reader *reader) location(start, end int) diagnostics.Location {
+	line, column := reader.position(start)
+	endLine, endColumn := reader.position(end - 1)
+	if end <= start {
+		endLine, endColumn = line, column
+	}
+	return diagnostics.Location{Line: line, Column: column, EndLine: endLine, EndColumn: endColumn}
+}
+
+func (reader *reader) position(offset int) (int, int) {
+	line := 1 + bytes.Count(reader.source[:offset], []byte("\n"))
+	lineStart := bytes.LastIndexByte(reader.source[:offset], '\n') + 1
+	return line, offset - lineStart + 1
+}
+
+func isIdentifier(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, r := range name {
+		letter := r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+		if !letter && (i == 0 || r < '0' || r > '9') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,206 @@
+package scxml
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/larkvincer/dsl-fsm/diagnostics"
+)
+
+const turnstile = `<?xml version="1.0" encoding="UTF-8"?>
+<scxml xmlns="http://www.w3.org/2005/07/scxml" version="1.0"
+       xmlns:smc="https://github.com/larkvincer/dsl-fsm" smc:actions="Turnstile"
+       name="Turnstile" initial="Locked">
+  <datamodel/>
+  <state id="Base">
+    <transition event="Reset" target="Locked">
+      <script>lock();</script>
+    </transition>
+    <state id="Locked">
+      <onentry>
+        <script>alarmOff(); beep();</script>
+      </onentry>
+      <transition event="Coin Card" target="Unlocked">
+        <script>unlock();</script>
+        <script>log();</script>
+      </transition>
+    </state>
+    <state id="Unlocked">
+      <onexit><script>lock();</script></onexit>
+      <transition event="Pass" target="Locked"/>
+      <transition event="Ping"/>
+    </state>
+  </state>
+</scxml>
+`
+
+func TestRead(t *testing.T) {
+	syntax, err := Read(strings.NewReader(turnstile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "" +
+		"FSM:Turnstile\n" +
+		"Initial:Locked\n" +
+		"Actions:Turnstile\n" +
+		"{\n" +
+		"  (Base) Reset Locked lock\n" +
+		"  Locked:Base <alarmOff <beep {\n" +
+		"    Coin Unlocked {unlock log}\n" +
+		"    Card Unlocked {unlock log}\n" +
+		"  }\n" +
+		"  Unlocked:Base >lock {\n" +
+		"    Pass Locked {}\n" +
+		"    Ping * {}\n" +
+		"  }\n" +
+		"}\n" +
+		".\n"
+	if syntax.String() != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, syntax.String())
+	}
+}
+
+func TestReadDefaultsToFirstState(t *testing.T) {
+	syntax, err := Read(strings.NewReader(`<scxml name="f"><state id="a"><state id="b"/></state><state id="c"/></scxml>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if syntax.Headers[1].Value != "b" {
+		t.Errorf("expected initial state b, got %v", syntax.Headers)
+	}
+}
+
+func TestReadEntersCompoundTargets(t *testing.T) {
+	document := `<scxml initial="Door" name="f">
+  <state id="Door" initial="Closed">
+    <state id="Open"><transition event="close" target="Door"/></state>
+    <state id="Closed"><transition event="open" target="Lock"/></state>
+  </state>
+  <state id="Lock">
+    <state id="Inner">
+      <state id="Bolted"><transition event="unbolt" target="Open"/></state>
+    </state>
+  </state>
+</scxml>`
+	syntax, err := Read(strings.NewReader(document))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "" +
+		"FSM:f\n" +
+		"Initial:Closed\n" +
+		"{\n" +
+		"  (Door) {\n  }\n" +
+		"  Open:Door close Closed {}\n" +
+		"  Closed:Door open Bolted {}\n" +
+		"  (Lock) {\n  }\n" +
+		"  (Inner):Lock {\n  }\n" +
+		"  Bolted:Inner unbolt Open {}\n" +
+		"}\n" +
+		".\n"
+	if syntax.String() != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, syntax.String())
+	}
+}
+
+func TestReadLocations(t *testing.T) {
+	document := "<scxml name=\"f\" initial=\"a\">\n" +
+		"  <state id=\"a\">\n" +
+		"    <transition event=\"x  y\"\n" +
+		"                target='b'/>\n" +
+		"  </state>\n" +
+		"</scxml>\n"
+	syntax, err := Read(strings.NewReader(document))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if location := syntax.Headers[1].ValueLocation; location != (diagnostics.Location{Line: 1, Column: 26, EndLine: 1, EndColumn: 26}) {
+		t.Errorf("unexpected initial location %+v", location)
+	}
+	state := syntax.Logic[0]
+	if location := state.State.NameLocation; location != (diagnostics.Location{Line: 2, Column: 14, EndLine: 2, EndColumn: 14}) {
+		t.Errorf("unexpected state location %+v", location)
+	}
+	if location := state.SubTransitions[1].EventLocation; location != (diagnostics.Location{Line: 3, Column: 27, EndLine: 3, EndColumn: 27}) {
+		t.Errorf("unexpected event location %+v", location)
+	}
+	if location := state.SubTransitions[0].NextStateLocation; location != (diagnostics.Location{Line: 4, Column: 25, EndLine: 4, EndColumn: 25}) {
+		t.Errorf("unexpected target location %+v", location)
+	}
+
+	_, err = Read(strings.NewReader("<scxml>\n  <state id=\"a\">\n    <log/>\n  </state>\n</scxml>"))
+	if err == nil || err.Error() != "line 3: unsupported SCXML element <log> in state a" {
+		t.Errorf("expected the error to name line 3, got %v", err)
+	}
+	expected := diagnostics.Diagnostic{
+		Severity: diagnostics.Error,
+		Code:     UNSUPPORTED_SCXML,
+		Message:  "unsupported SCXML element <log> in state a",
+		Location: diagnostics.Location{Line: 3, Column: 6, EndLine: 3, EndColumn: 8},
+	}
+	if readError, ok := err.(*Error); !ok || !reflect.DeepEqual(readError.Diagnostic(), expected) {
+		t.Errorf("expected diagnostic %+v, got %#v", expected, err)
+	}
+
+	_, err = Read(strings.NewReader("<scxml>\n  <state id=\"a\">\n</scxml>"))
+	if readError, ok := err.(*Error); !ok || readError.Code != INVALID_XML || readError.Location.Line != 3 {
+		t.Errorf("expected an INVALID_XML error on line 3, got %#v", err)
+	}
+}
+
+func TestReadRejectsWhatTheLanguageCannotExpress(t *testing.T) {
+	for name, test := range map[string]struct{ document, message string }{
+		"condition": {
+			`<scxml><state id="a"><transition event="e" cond="x" target="a"/></state></scxml>`,
+			"transition of state a has a condition",
+		},
+		"eventless transition": {
+			`<scxml><state id="a"><transition target="a"/></state></scxml>`,
+			"transition of state a has no event",
+		},
+		"targetless transition of a compound state": {
+			`<scxml><state id="a"><transition event="e"/><state id="b"/></state></scxml>`,
+			"transition of compound state a on e has no target",
+		},
+		"several targets": {
+			`<scxml><state id="a"><transition event="e" target="a b"/></state></scxml>`,
+			"needs exactly one target",
+		},
+		"parallel": {
+			`<scxml><state id="a"><parallel id="p"/></state></scxml>`,
+			"unsupported SCXML element <parallel> in state a",
+		},
+		"executable content": {
+			`<scxml><state id="a"><onentry><log expr="'hi'"/></onentry></state></scxml>`,
+			"unsupported SCXML element <log> in state a",
+		},
+		"script": {
+			`<scxml><state id="a"><onexit><script>x = 1;</script></onexit></state></scxml>`,
+			"is not a list of action calls",
+		},
+		"parallel target": {
+			`<scxml><state id="a"><transition event="e" target="p"/></state><parallel id="p"/></scxml>`,
+			"p is a <parallel>, which the state machine language cannot enter",
+		},
+		"initial cycle": {
+			`<scxml><state id="a" initial="b"><state id="b" initial="a"/></state></scxml>`,
+			"the initial substates of a form a cycle",
+		},
+		"missing id": {
+			`<scxml><state/></scxml>`,
+			"<state> without an id",
+		},
+		"namespace": {
+			`<scxml xmlns="urn:other"><state id="a"/></scxml>`,
+			"not in the SCXML namespace",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := Read(strings.NewReader(test.document))
+			if err == nil || !strings.Contains(err.Error(), test.message) {
+				t.Fatalf("expected an error containing %q, got %v", test.message, err)
+			}
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"sort"
 	"strings"
 
+	"github.com/larkvincer/dsl-fsm/diagnostics"
 	"github.com/larkvincer/dsl-fsm/parser"
 )
 
@@ -47,17 +48,18 @@ func (sa *SemanticAnalyzer) setHeaders(fsmSyntax *parser.FsmSyntax) {
 		} else if isNamed(header, "initial") {
 			sa.setHeader(&sa.initialHeader, header)
 		} else {
-			sa.semanticStateMachine.addError(NewAnalysisErrorWithExtra(INVALID_HEADER, header.String()))
+			sa.semanticStateMachine.addError(NewAnalysisErrorAt(INVALID_HEADER, header.String(), header.Location()))
 		}
 	}
 }
 
 func (sa *SemanticAnalyzer) setHeader(targetHeader *parser.Header, header parser.Header) {
 	if isNullHeader(targetHeader) {
-		targetHeader.Name = header.Name
-		targetHeader.Value = header.Value
+		*targetHeader = header
 	} else {
-		sa.semanticStateMachine.addError(NewAnalysisErrorWithExtra(EXTRA_HEADER_IGNORED, header.String()))
+		sa.semanticStateMachine.addError(
+			NewAnalysisErrorAt(EXTRA_HEADER_IGNORED, header.String(), header.Location(), targetHeader.Location()),
+		)
 	}
 }
 
@@ -83,45 +85,65 @@ func (sa *SemanticAnalyzer) checkSemanticValidity(fsmSyntax *parser.FsmSyntax) {
 func (sa *SemanticAnalyzer) checkForInconsistentAbstraction(fsmSyntax *parser.FsmSyntax) {
 	abstractStates := sa.findAbstractStates(fsmSyntax)
 	for _, transition := range fsmSyntax.Logic {
-		if _, ok := abstractStates[transition.State.Name]; !transition.State.AbstractState && ok {
+		if abstractState, ok := abstractStates[transition.State.Name]; !transition.State.AbstractState && ok {
 			sa.semanticStateMachine.Warnings = append(
 				sa.semanticStateMachine.Warnings,
-				*NewAnalysisErrorWithExtra(INCONSISTENT_ABSTRACTION, transition.State.Name),
+				*NewAnalysisErrorAt(
+					INCONSISTENT_ABSTRACTION, transition.State.Name,
+					transition.State.NameLocation, abstractState.State.NameLocation,
+				),
 			)
 		}
 	}
 }
 
 func (sa *SemanticAnalyzer) checkForMultiplyDefinedStateActions(fsmSyntax *parser.FsmSyntax) {
-	firstActionsForState := make(map[string]string)
+	firstActionsForState := make(map[string]*parser.FsmTransition)
 	for _, transition := range fsmSyntax.Logic {
 		if specifiesStateActions(transition) {
 			actionsKey := makeActionsKey(transition)
-			if _, ok := firstActionsForState[transition.State.Name]; ok {
-				if ac := firstActionsForState[transition.State.Name]; ac != actionsKey {
+			if first, ok := firstActionsForState[transition.State.Name]; ok {
+				if makeActionsKey(first) != actionsKey {
 					sa.semanticStateMachine.Errors = append(
 						sa.semanticStateMachine.Errors,
-						*NewAnalysisErrorWithExtra(STATE_ACTIONS_MULTIPLY_DEFINED, transition.State.Name),
+						*NewAnalysisErrorAt(
+							STATE_ACTIONS_MULTIPLY_DEFINED, transition.State.Name,
+							transition.State.ActionsLocation(), first.State.ActionsLocation(),
+						),
 					)
 				}
 			} else {
-				firstActionsForState[transition.State.Name] = actionsKey
+				firstActionsForState[transition.State.Name] = transition
 			}
 		}
 	}
 }
 
+// checkThatAbstractStatesAreNotTargets also rejects a "*" transition of an
+// abstract state: "*" names the state that declares it, and the machine
+// can never rest in an abstract state. Staying in whichever substate the
+// machine is in would need transitions that neither exit nor enter, which
+// the language does not have.
 func (sa *SemanticAnalyzer) checkThatAbstractStatesAreNotTargets(fsmSyntax *parser.FsmSyntax) {
 	abstractStates := sa.findAbstractStates(fsmSyntax)
 
 	for _, transition := range fsmSyntax.Logic {
 		for _, subTransition := range transition.SubTransitions {
-			if _, ok := abstractStates[subTransition.NextState]; ok {
+			nextState := subTransition.NextState
+			if nextState == "" && subTransition.Event != "" {
+				nextState = transition.State.Name
+			}
+			if abstractState, ok := abstractStates[nextState]; ok {
+				if subTransition.NextState == "" {
+					nextState = "*"
+				}
 				sa.semanticStateMachine.Errors = append(
 					sa.semanticStateMachine.Errors,
-					*NewAnalysisErrorWithExtra(
+					*NewAnalysisErrorAt(
 						ABSTRACT_STATE_USED_AS_NEXT_STATE,
-						fmt.Sprintf("%s(%s)->%s", transition.State.Name, subTransition.Event, subTransition.NextState),
+						fmt.Sprintf("%s(%s)->%s", transition.State.Name, subTransition.Event, nextState),
+						subTransition.NextStateLocation,
+						abstractState.State.NameLocation,
 					),
 				)
 			}
@@ -129,12 +151,12 @@ func (sa *SemanticAnalyzer) checkThatAbstractStatesAreNotTargets(fsmSyntax *pars
 	}
 }
 
-func (sa *SemanticAnalyzer) findAbstractStates(fsmSyntax *parser.FsmSyntax) map[string]bool {
-	abstractStates := make(map[string]bool)
+func (sa *SemanticAnalyzer) findAbstractStates(fsmSyntax *parser.FsmSyntax) map[string]*parser.FsmTransition {
+	abstractStates := make(map[string]*parser.FsmTransition)
 
 	for _, transition := range fsmSyntax.Logic {
-		if transition.State.AbstractState {
-			abstractStates[transition.State.Name] = true
+		if _, ok := abstractStates[transition.State.Name]; transition.State.AbstractState && !ok {
+			abstractStates[transition.State.Name] = transition
 		}
 	}
 
@@ -142,17 +164,17 @@ func (sa *SemanticAnalyzer) findAbstractStates(fsmSyntax *parser.FsmSyntax) map[
 }
 
 func (sa *SemanticAnalyzer) checkForDuplicateTransitions(fsmSyntax *parser.FsmSyntax) {
-	transitionKeys := make(map[string]bool)
+	transitionKeys := make(map[string]diagnostics.Location)
 	for _, transition := range fsmSyntax.Logic {
 		for _, subTransition := range transition.SubTransitions {
 			key := fmt.Sprintf("%s(%s)", transition.State.Name, subTransition.Event)
-			if _, ok := transitionKeys[key]; ok {
+			if first, ok := transitionKeys[key]; ok {
 				sa.semanticStateMachine.Errors = append(
 					sa.semanticStateMachine.Errors,
-					*NewAnalysisErrorWithExtra(DUPLICATE_TRANSITION, key),
+					*NewAnalysisErrorAt(DUPLICATE_TRANSITION, key, subTransition.EventLocation, first),
 				)
 			} else {
-				transitionKeys[key] = true
+				transitionKeys[key] = subTransition.EventLocation
 			}
 		}
 	}
@@ -173,7 +195,7 @@ func (sa *SemanticAnalyzer) findStatesDefinedButNotUsed(usedStates map[string]bo
 		if _, ok := usedStates[definedState]; !ok {
 			sa.semanticStateMachine.Errors = append(
 				sa.semanticStateMachine.Errors,
-				*NewAnalysisErrorWithExtra(UNUSED_STATE, definedState),
+				*NewAnalysisErrorAt(UNUSED_STATE, definedState, sa.semanticStateMachine.States[definedState].Location),
 			)
 		}
 	}
@@ -230,8 +252,10 @@ func (sa *SemanticAnalyzer) createStateEventAndActionLists(fsmSyntax *parser.Fsm
 
 func (sa *SemanticAnalyzer) addStateNamesToStateList(fsmSyntax *parser.FsmSyntax) {
 	for _, transition := range fsmSyntax.Logic {
-		state := NewSemanticState(transition.State.Name)
-		sa.semanticStateMachine.States[state.Name] = state
+		if _, ok := sa.semanticStateMachine.States[transition.State.Name]; !ok {
+			state := NewSemanticStateAt(transition.State.Name, transition.State.NameLocation)
+			sa.semanticStateMachine.States[state.Name] = state
+		}
 	}
 }
 
@@ -269,27 +293,31 @@ func (sa *SemanticAnalyzer) addTransitionActionsToActionList(fsmSyntax *parser.F
 
 func (sa *SemanticAnalyzer) checkUndefinedStates(fsmSyntax *parser.FsmSyntax) {
 	for _, transition := range fsmSyntax.Logic {
-		for _, superState := range transition.State.SuperStates {
-			sa.checkUndefinedState(superState, UNDEFINED_SUPER_STATE)
+		for i, superState := range transition.State.SuperStates {
+			sa.checkUndefinedState(superState, UNDEFINED_SUPER_STATE, transition.State.SuperStateLocations[i])
 		}
 
 		for _, subTransition := range transition.SubTransitions {
-			sa.checkUndefinedState(subTransition.NextState, UNDEFINED_STATE)
+			sa.checkUndefinedState(subTransition.NextState, UNDEFINED_STATE, subTransition.NextStateLocation)
 		}
 	}
 
 	if _, ok := sa.semanticStateMachine.States[sa.initialHeader.Value]; !ok && sa.initialHeader.Value != "" {
 		sa.semanticStateMachine.Errors = append(sa.semanticStateMachine.Errors,
-			*NewAnalysisErrorWithExtra(UNDEFINED_STATE, "initial: "+sa.initialHeader.Value),
+			*NewAnalysisErrorAt(UNDEFINED_STATE, "initial: "+sa.initialHeader.Value, sa.initialHeader.ValueLocation),
 		)
 	}
 }
 
-func (sa *SemanticAnalyzer) checkUndefinedState(referenceState string, errorCode ErrorId) {
+func (sa *SemanticAnalyzer) checkUndefinedState(
+	referenceState string,
+	errorCode ErrorId,
+	location diagnostics.Location,
+) {
 	if _, ok := sa.semanticStateMachine.States[referenceState]; !ok && referenceState != "" {
 		sa.semanticStateMachine.Errors = append(
 			sa.semanticStateMachine.Errors,
-			*NewAnalysisErrorWithExtra(errorCode, referenceState),
+			*NewAnalysisErrorAt(errorCode, referenceState, location),
 		)
 	}
 }
@@ -333,6 +361,7 @@ func (sa *SemanticAnalyzer) compileTransitions(transition *parser.FsmTransition,
 func (sa *SemanticAnalyzer) compileTransition(state *SemanticState, subTransition *parser.SubTransition) {
 	semanticTransition := SemanticTransition{}
 	semanticTransition.Event = subTransition.Event
+	semanticTransition.Location = subTransition.EventLocation
 	if subTransition.NextState == "" {
 		semanticTransition.NextState = state
 	} else {
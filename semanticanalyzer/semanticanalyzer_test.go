@@ -4,6 +4,7 @@ import (
 	"reflect"
 	"testing"
 
+	"github.com/larkvincer/dsl-fsm/diagnostics"
 	"github.com/larkvincer/dsl-fsm/lexer"
 	"github.com/larkvincer/dsl-fsm/parser"
 )
@@ -126,6 +127,26 @@ func TestStateErrors(t *testing.T) {
 			emptyErrors,
 			[]AnalysisError{*NewAnalysisErrorWithExtra(CONFLICTING_SUPERSTATES, "s|e1")},
 		},
+		{"no error for same event in first unrelated state",
+			"FSM: f Actions: act Initial: s1 {s1 e s2 a1 s2 e s1 a2}",
+			emptyErrors,
+			[]AnalysisError{*NewAnalysisErrorWithExtra(CONFLICTING_SUPERSTATES, "s1|e")},
+		},
+		{"no error for same event in second unrelated state",
+			"FSM: f Actions: act Initial: s1 {s1 e s2 a1 s2 e s1 a2}",
+			emptyErrors,
+			[]AnalysisError{*NewAnalysisErrorWithExtra(CONFLICTING_SUPERSTATES, "s2|e")},
+		},
+		{"no error for first substate overriding a shared superstate transition",
+			"FSM: f Actions: act Initial: s1 {(ss) e x * s1:ss e s1 * s2:ss e s2 * x * * *}",
+			emptyErrors,
+			[]AnalysisError{*NewAnalysisErrorWithExtra(CONFLICTING_SUPERSTATES, "s1|e")},
+		},
+		{"no error for second substate overriding a shared superstate transition",
+			"FSM: f Actions: act Initial: s1 {(ss) e x * s1:ss e s1 * s2:ss e s2 * x * * *}",
+			emptyErrors,
+			[]AnalysisError{*NewAnalysisErrorWithExtra(CONFLICTING_SUPERSTATES, "s2|e")},
+		},
 		{"error if super states have different actions in same transitions",
 			"" +
 				"FSM: f Actions: act Initial: s" +
@@ -158,6 +179,14 @@ func TestTransitionErrors(t *testing.T) {
 			[]AnalysisError{*NewAnalysisErrorWithExtra(ABSTRACT_STATE_USED_AS_NEXT_STATE, "s(e)->as")},
 			emptyErrors,
 		},
+		{"abstract states can not stay in themselves", "{(as) e * * s:as f s *}",
+			[]AnalysisError{*NewAnalysisErrorWithExtra(ABSTRACT_STATE_USED_AS_NEXT_STATE, "as(e)->*")},
+			emptyErrors,
+		},
+		{"abstract states can declare entry and exit actions without transitions", "{(as) <n >x * * * s:as f s *}",
+			emptyErrors,
+			[]AnalysisError{*NewAnalysisErrorWithExtra(ABSTRACT_STATE_USED_AS_NEXT_STATE, "as()->*")},
+		},
 		{"entry and exit actions are not multiply defined",
 			"" +
 				"{" +
@@ -227,6 +256,79 @@ func TestWarnings(t *testing.T) {
 	}
 }
 
+func TestDiagnostics(t *testing.T) {
+	got := produceSemanticStateMachine("{(ias) e * * ias e * * s e x *}").Diagnostics()
+
+	expected := []diagnostics.Diagnostic{
+		{
+			Severity: diagnostics.Error,
+			Code:     string(UNDEFINED_STATE),
+			Message:  "undefined state x",
+			Location: diagnostics.Location{Line: 1, Column: 28, EndLine: 1, EndColumn: 28},
+		},
+		{
+			Severity: diagnostics.Warning,
+			Code:     string(INCONSISTENT_ABSTRACTION),
+			Message:  "state ias is used both as abstract and concrete",
+			Location: diagnostics.Location{Line: 1, Column: 14, EndLine: 1, EndColumn: 16},
+			Related:  []diagnostics.Location{{Line: 1, Column: 3, EndLine: 1, EndColumn: 5}},
+		},
+	}
+	for _, expectedDiagnostic := range expected {
+		found := false
+		for _, diagnostic := range got {
+			if reflect.DeepEqual(diagnostic, expectedDiagnostic) {
+				found = true
+			}
+		}
+		if !found {
+			t.Fatalf("expected '%v', but got '%v'", expectedDiagnostic, got)
+		}
+	}
+}
+
+func TestErrorLocations(t *testing.T) {
+	type locationTest struct {
+		name     string
+		source   string
+		errorId  ErrorId
+		expected diagnostics.Location
+	}
+
+	const source = "" +
+		"Initial: i\n" +
+		"{\n" +
+		"  i e s2 *\n" +
+		"  i e i *\n" +
+		"  s:ss e i *\n" +
+		"}"
+
+	testTable := []locationTest{
+		{"undefined next state", source, UNDEFINED_STATE, diagnostics.Location{Line: 3, Column: 7, EndLine: 3, EndColumn: 8}},
+		{"undefined super state", source, UNDEFINED_SUPER_STATE, diagnostics.Location{Line: 5, Column: 5, EndLine: 5, EndColumn: 6}},
+		{"duplicate transition", source, DUPLICATE_TRANSITION, diagnostics.Location{Line: 4, Column: 5, EndLine: 4, EndColumn: 5}},
+		{"unused state", source, UNUSED_STATE, diagnostics.Location{Line: 5, Column: 3, EndLine: 5, EndColumn: 3}},
+		{"undefined initial state", "Initial: x {s e s *}", UNDEFINED_STATE, diagnostics.Location{Line: 1, Column: 10, EndLine: 1, EndColumn: 10}},
+		{"invalid header spans name and value", "X: abc {}", INVALID_HEADER, diagnostics.Location{Line: 1, Column: 1, EndLine: 1, EndColumn: 6}},
+		{"state actions multiply defined span the actions", "Initial: s {s <a e s * s <b >c f s *}",
+			STATE_ACTIONS_MULTIPLY_DEFINED, diagnostics.Location{Line: 1, Column: 27, EndLine: 1, EndColumn: 30}},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			for _, analysisError := range produceSemanticStateMachine(testCase.source).Errors {
+				if analysisError.ErrorId() == testCase.errorId {
+					if analysisError.Location() != testCase.expected {
+						t.Fatalf("expected %v at '%v', but got '%v'", testCase.errorId, testCase.expected, analysisError.Location())
+					}
+					return
+				}
+			}
+			t.Fatalf("expected %v for %s", testCase.errorId, testCase.source)
+		})
+	}
+}
+
 func TestFSMElements(t *testing.T) {
 
 	t.Run("states", func(t *testing.T) {
@@ -637,7 +739,7 @@ func contains(target, toCheck []AnalysisError) bool {
 	for _, error := range toCheck {
 		found := false
 		for _, targetError := range target {
-			if reflect.DeepEqual(error, targetError) {
+			if error.errorId == targetError.errorId && error.extra == targetError.extra {
 				found = true
 				break
 			}
@@ -3,6 +3,9 @@ package semanticanalyzer
 import (
 	"fmt"
 	"sort"
+	"strings"
+
+	"github.com/larkvincer/dsl-fsm/diagnostics"
 )
 
 type SemanticStateMachine struct {
@@ -30,6 +33,17 @@ func (ssm *SemanticStateMachine) addError(analysisError *AnalysisError) {
 	ssm.Errors = append(ssm.Errors, *analysisError)
 }
 
+func (ssm *SemanticStateMachine) Diagnostics() []diagnostics.Diagnostic {
+	result := []diagnostics.Diagnostic{}
+	for _, analysisError := range ssm.Errors {
+		result = append(result, analysisError.Diagnostic(diagnostics.Error))
+	}
+	for _, warning := range ssm.Warnings {
+		result = append(result, warning.Diagnostic(diagnostics.Warning))
+	}
+	return result
+}
+
 func (ssm *SemanticStateMachine) String() string {
 	return fmt.Sprintf(
 		""+
@@ -60,6 +74,7 @@ func (ssm *SemanticStateMachine) statesToString() string {
 
 type SemanticState struct {
 	Name          string
+	Location      diagnostics.Location
 	EntryActions  []string
 	ExitActions   []string
 	AbstractState bool
@@ -67,6 +82,12 @@ type SemanticState struct {
 	Transitions   []SemanticTransition
 }
 
+func NewSemanticStateAt(name string, location diagnostics.Location) *SemanticState {
+	state := NewSemanticState(name)
+	state.Location = location
+	return state
+}
+
 func NewSemanticState(name string) *SemanticState {
 	return &SemanticState{Name: name, SuperStates: make(map[*SemanticState]bool)}
 }
@@ -155,8 +176,10 @@ const (
 )
 
 type AnalysisError struct {
-	errorId ErrorId
-	extra   string
+	errorId  ErrorId
+	extra    string
+	location diagnostics.Location
+	related  []diagnostics.Location
 }
 
 func NewAnalysisError(errorId ErrorId) *AnalysisError {
@@ -173,8 +196,71 @@ func NewAnalysisErrorWithExtra(errorId ErrorId, extra string) *AnalysisError {
 	}
 }
 
+var errorMessages = map[ErrorId]string{
+	NO_FSM:                            "no FSM header",
+	NO_INITIAL:                        "no Initial header",
+	INVALID_HEADER:                    "invalid header %s",
+	EXTRA_HEADER_IGNORED:              "extra header %s ignored",
+	UNDEFINED_STATE:                   "undefined state %s",
+	UNDEFINED_SUPER_STATE:             "undefined super state %s",
+	UNUSED_STATE:                      "state %s is never used",
+	DUPLICATE_TRANSITION:              "duplicate transition %s",
+	ABSTRACT_STATE_USED_AS_NEXT_STATE: "abstract state used as next state in %s",
+	INCONSISTENT_ABSTRACTION:          "state %s is used both as abstract and concrete",
+	STATE_ACTIONS_MULTIPLY_DEFINED:    "entry and exit actions of state %s are defined more than once",
+	CONFLICTING_SUPERSTATES:           "super states define conflicting transitions for %s",
+}
+
+func NewAnalysisErrorAt(
+	errorId ErrorId,
+	extra string,
+	location diagnostics.Location,
+	related ...diagnostics.Location,
+) *AnalysisError {
+	return &AnalysisError{
+		errorId:  errorId,
+		extra:    extra,
+		location: location,
+		related:  related,
+	}
+}
+
+func (analysisError AnalysisError) ErrorId() ErrorId {
+	return analysisError.errorId
+}
+
+func (analysisError AnalysisError) Extra() string {
+	return analysisError.extra
+}
+
+func (analysisError AnalysisError) Location() diagnostics.Location {
+	return analysisError.location
+}
+
+func (analysisError AnalysisError) Diagnostic(severity diagnostics.Severity) diagnostics.Diagnostic {
+	message := errorMessages[analysisError.errorId]
+	if strings.Contains(message, "%s") {
+		message = fmt.Sprintf(message, analysisError.extra)
+	}
+	return diagnostics.Diagnostic{
+		Severity: severity,
+		Code:     string(analysisError.errorId),
+		Message:  message,
+		Location: analysisError.location,
+		Related:  append([]diagnostics.Location(nil), analysisError.related...),
+	}
+}
+
+func (analysisError AnalysisError) String() string {
+	if analysisError.extra == "" {
+		return string(analysisError.errorId)
+	}
+	return fmt.Sprintf("%s: %s", analysisError.errorId, analysisError.extra)
+}
+
 type SemanticTransition struct {
 	Event     string
 	NextState *SemanticState
 	Action    []string
+	Location  diagnostics.Location
 }
@@ -1,6 +1,10 @@
 package semanticanalyzer
 
-import "reflect"
+import (
+	"reflect"
+
+	"github.com/larkvincer/dsl-fsm/diagnostics"
+)
 
 type superClassCrawler struct {
 	concreteState    SemanticState
@@ -23,7 +27,9 @@ func (sc *superClassCrawler) checkSuperClassTransitions() {
 	for _, state := range values {
 		if !state.AbstractState {
 			sc.concreteState = state
-			// sc.transitionTuples = make(map[string]transitionTuple)
+			// Each concrete state inherits its own transitions; tuples left
+			// over from another state would be reported as conflicts.
+			sc.transitionTuples = make(map[string]transitionTuple)
 			sc.checkTransitionsForState(&sc.concreteState)
 		}
 	}
@@ -49,6 +55,7 @@ func (sc *superClassCrawler) checkTransitionForPreviousDefinition(
 	thisTuple := newTransitionTuple(
 		state.Name, semanticTransition.Event,
 		semanticTransition.NextState.Name, semanticTransition.Action,
+		semanticTransition.Location,
 	)
 	if _, ok := sc.transitionTuples[thisTuple.event]; ok {
 		sc.determineIfthePreviousDefinitionIsAnError(state, *thisTuple)
@@ -80,7 +87,10 @@ func (sc *superClassCrawler) checkForOverriddenTransition(
 	if !isSuperStateOf(definingState, state) {
 		sc.ssm.Errors = append(
 			sc.ssm.Errors,
-			*NewAnalysisErrorWithExtra(CONFLICTING_SUPERSTATES, sc.concreteState.Name+"|"+thisTuple.event),
+			*NewAnalysisErrorAt(
+				CONFLICTING_SUPERSTATES, sc.concreteState.Name+"|"+thisTuple.event,
+				sc.concreteState.Location, previousTuple.location, thisTuple.location,
+			),
 		)
 	} else {
 		sc.transitionTuples[thisTuple.event] = thisTuple
@@ -104,15 +114,17 @@ type transitionTuple struct {
 	event        string
 	nextState    string
 	actions      []string
+	location     diagnostics.Location
 }
 
 func newTransitionTuple(
-	currentState, event, nextState string, actions []string,
+	currentState, event, nextState string, actions []string, location diagnostics.Location,
 ) *transitionTuple {
 	return &transitionTuple{
 		currentState: currentState,
 		event:        event,
 		nextState:    nextState,
 		actions:      actions,
+		location:     location,
 	}
 }